@@ -31,14 +31,16 @@ type CommandBaseInterface interface {
 	printDescription()
 }
 
-// SubCommand - sub command type of basic command, in addition it can hold a slice of next commands
+// SubCommand - sub command type of basic command, in addition it can hold a slice of next commands and default
+// command called when none of next commands matches
 type SubCommand struct {
 	CommandBase
-	nextCommands []CommandBaseInterface
+	nextCommands   []CommandBaseInterface
+	defaultCommand CommandBaseInterface
 }
 
 // CommandAction - defines function type that is called on command
-type CommandAction func()
+type CommandAction func() error
 
 // Command - command type of basic command, in addition it holds a pointer on function (defined by CommandAction)
 type Command struct {
@@ -46,23 +48,53 @@ type Command struct {
 	cAction CommandAction
 }
 
-// FlagProperties - holds poiner that points to given flag value and information if this specisic flag is required
+// FlagProperties - holds poiner that points to given flag value and information if this specisic flag is required.
+// Values holds all values of repeated flag or elements of list flag.
 type FlagProperties struct {
 	Value      *string
 	IsRequired bool
+	Values     *[]string
 }
 
 // FlagMap key - is command name
 type FlagMap map[string]FlagProperties
 
 // ParsedAction - defines function type that is called on FlaggedCommand
-type ParsedAction func(flagMap FlagMap)
+type ParsedAction func(flagMap FlagMap) error
+
+// UsageError - error of wrong command line usage (unknown command, missing or invalid flags)
+type UsageError struct {
+	message string
+}
+
+// ErrHelp - returned when help was requested and printed instead of calling command
+var ErrHelp = errors.New("Print help")
+
+func (err *UsageError) Error() string {
+
+	return err.message
+}
+
+// Creates new usage error with given message
+func NewUsageError(message string) error {
+
+	return &UsageError{message}
+}
+
+// Check if error is caused by wrong command line usage
+func IsUsageError(err error) bool {
+
+	_, ok := err.(*UsageError)
+
+	return ok
+}
 
 // FlaggedCommand - command that specifies command additional flags
 type FlaggedCommand struct {
 	CommandBase
 	valueWithoutFlag string
 	flagMap          FlagMap
+	flagTypes        FlagTypes
 	pAction          ParsedAction
 	pFlag            *flag.FlagSet
 }
@@ -102,6 +134,17 @@ func NewSubCommand(name string, commands []CommandBaseInterface, description str
 	return subCommand
 }
 
+// Creates new sub command with default command, which has the same name as sub command and is called when
+// none of next commands matches (e.g. flagged command called with flags only)
+func NewSubCommandWithDefault(name string, commands []CommandBaseInterface, defaultCommand CommandBaseInterface,
+	description string) *SubCommand {
+
+	subCommand := NewSubCommand(name, commands, description)
+	subCommand.defaultCommand = defaultCommand
+
+	return subCommand
+}
+
 func (sCmd *SubCommand) checkAndParse(argv []string, argDepth int) (bool, error) {
 
 	if argDepth < len(argv) && argv[argDepth] == sCmd.name {
@@ -112,8 +155,15 @@ func (sCmd *SubCommand) checkAndParse(argv []string, argDepth int) (bool, error)
 			}
 		}
 
+		if sCmd.defaultCommand != nil {
+			if res, err := sCmd.defaultCommand.checkAndParse(argv, argDepth); res {
+
+				return true, err
+			}
+		}
+
 		sCmd.printSubcommands()
-		return true, errors.New("Cannot match any sub command")
+		return true, NewUsageError("Cannot match any sub command")
 	}
 
 	return false, nil
@@ -152,6 +202,10 @@ func (sCmd *SubCommand) printDescription() {
 			subCommands += "|" + cmd.getName()
 		}
 	}
+	if sCmd.defaultCommand != nil {
+
+		subCommands += "|"
+	}
 	subCommands += ") "
 
 	subCommandsC.Print(subCommands)
@@ -176,7 +230,7 @@ func (cmd *Command) checkAndParse(argv []string, argDepth int) (bool, error) {
 		if argv[argDepth] == cmd.name {
 
 			if cmd.cAction != nil {
-				cmd.cAction()
+				return true, cmd.cAction()
 			}
 			return true, nil
 		}
@@ -207,6 +261,7 @@ func NewFlaggedCommand(name string, valueWithoutFlag string, action ParsedAction
 	flaggedCommand.pFlag = flag.NewFlagSet(name, flag.ContinueOnError)
 	flaggedCommand.pFlag.SetOutput(ioutil.Discard)
 	flaggedCommand.flagMap = FlagMap{}
+	flaggedCommand.flagTypes = FlagTypes{}
 
 	if valueWithoutFlag != "" {
 
@@ -215,8 +270,10 @@ func NewFlaggedCommand(name string, valueWithoutFlag string, action ParsedAction
 
 	for key, val := range flags {
 
-		pVal := flaggedCommand.pFlag.String(key, "", "Required: "+strconv.FormatBool(val))
-		flaggedCommand.flagMap[key] = FlagProperties{pVal, val}
+		pVal := new(string)
+		pValues := &[]string{}
+		flaggedCommand.pFlag.Var(&flagValue{pVal, pValues}, key, "Required: "+strconv.FormatBool(val))
+		flaggedCommand.flagMap[key] = FlagProperties{Value: pVal, IsRequired: val, Values: pValues}
 	}
 
 	return flaggedCommand
@@ -232,46 +289,124 @@ func (fCmd *FlaggedCommand) checkAndParse(argv []string, argDepth int) (bool, er
 		if isNextHelp(argv, argDepth) {
 
 			fCmd.printFlags(true)
-			return true, errors.New("Print help")
+			return true, ErrHelp
 		}
 
 		if !isNextFlag(argv, argDepth) {
 
 			nextArgIdx := argDepth + actualArgIdx
-			fCmd.flagMap[fCmd.valueWithoutFlag] = FlagProperties{&argv[nextArgIdx], fCmd.flagMap[fCmd.valueWithoutFlag].IsRequired}
+			fCmd.flagMap[fCmd.valueWithoutFlag] = FlagProperties{Value: &argv[nextArgIdx],
+				IsRequired: fCmd.flagMap[fCmd.valueWithoutFlag].IsRequired, Values: &[]string{}}
 			actualArgIdx++
 		}
 
+		// values of previous parse are not collected again
+		for _, props := range fCmd.flagMap {
+			if props.Values != nil {
+				*props.Values = nil
+			}
+		}
+
 		if argDepth+actualArgIdx < len(argv) {
-			fCmd.pFlag.Parse(argv[argDepth+actualArgIdx:])
+
+			args, err := fCmd.setSwitchValues(argv[argDepth+actualArgIdx:])
+			if err != nil {
+
+				fCmd.printFlags(false)
+				return true, err
+			}
+
+			if err := fCmd.pFlag.Parse(args); err != nil {
+
+				fCmd.printFlags(true)
+
+				if err == flag.ErrHelp {
+					return true, ErrHelp
+				}
+
+				return true, NewUsageError(err.Error())
+			}
 		}
 
 		if fCmd.isAnyRequiredNotSet() {
 
 			fCmd.printFlags(false)
-			return true, errors.New("Some required flag not set")
+			return true, NewUsageError("Some required flag not set")
+		}
+
+		if err := fCmd.validateFlags(); err != nil {
+
+			return true, err
 		}
 
-		fCmd.pAction(fCmd.flagMap)
-		return true, nil
+		return true, fCmd.pAction(fCmd.flagMap)
 
 	} else if argDepth < len(argv) && argv[argDepth] == fCmd.name {
 
 		if fCmd.isAnyFlagRequired() {
 
 			fCmd.printFlags(false)
-			return true, errors.New("No flag set")
+			return true, NewUsageError("No flag set")
 
 		} else {
 
-			fCmd.pAction(fCmd.flagMap)
-			return true, nil
+			return true, fCmd.pAction(fCmd.flagMap)
 		}
 	}
 
 	return false, nil
 }
 
+// bool flag passed without value (as last argument or followed by other flag) is treated as switch and set to
+// "true", other flags without value are usage error
+func (fCmd *FlaggedCommand) setSwitchValues(argv []string) ([]string, error) {
+
+	args := []string{}
+
+	for idx, arg := range argv {
+
+		if fCmd.isFlagArg(arg) && !strings.Contains(arg, "=") {
+			if idx+1 == len(argv) || fCmd.isFlagArg(argv[idx+1]) {
+
+				name := flagArgName(arg)
+				if fCmd.flagTypes[name].Type != BoolFlag {
+
+					return nil, NewUsageError("Missing value of -" + name)
+				}
+
+				arg += "=true"
+			}
+		}
+
+		args = append(args, arg)
+	}
+
+	return args, nil
+}
+
+// checks if argument is one of command flags (-flag, --flag or -flag=value)
+func (fCmd *FlaggedCommand) isFlagArg(arg string) bool {
+
+	if !strings.HasPrefix(arg, "-") {
+
+		return false
+	}
+
+	return fCmd.pFlag.Lookup(flagArgName(arg)) != nil
+}
+
+// Get name of flag argument (-flag, --flag or -flag=value)
+func flagArgName(arg string) string {
+
+	name := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+	if eqIdx := strings.Index(name, "="); eqIdx >= 0 {
+
+		name = name[:eqIdx]
+	}
+
+	return name
+}
+
 func (fCmd *FlaggedCommand) printFlags(printAll bool) {
 
 	commandNameC.Println()
@@ -291,10 +426,15 @@ func (fCmd *FlaggedCommand) printFlags(printAll bool) {
 
 			fmt.Print(strings.Repeat(" ", 3))
 
-			if val.IsRequired {
-				requiredFlagC.Println("-" + key + " " + "<" + strings.ToUpper(key) + "> (required)")
+			placeholder := fCmd.flagTypes[key].placeholder(key)
+
+			if fileFlag := fCmd.inFileFlag(key); val.IsRequired && fileFlag != "" {
+				requiredFlagC.Println("-" + key + " " + placeholder + " (required unless set in -" + fileFlag +
+					" file)")
+			} else if val.IsRequired {
+				requiredFlagC.Println("-" + key + " " + placeholder + " (required)")
 			} else {
-				notRequiredFlagC.Println("-" + key + " " + "<" + strings.ToUpper(key) + "> ")
+				notRequiredFlagC.Println("-" + key + " " + placeholder + " ")
 			}
 		}
 	}
@@ -321,13 +461,26 @@ func (fCmd *FlaggedCommand) printDescription() {
 		requiredFlagC.Print("-" + fCmd.valueWithoutFlag + " " + "<" + strings.ToUpper(fCmd.valueWithoutFlag) + "> ")
 	}
 
+	inFileFlags := map[string]string{}
+
 	for key, val := range fCmd.flagMap {
 
-		if val.IsRequired && key != fCmd.valueWithoutFlag {
-			flags += "-" + key + " " + "<" + strings.ToUpper(key) + "> "
+		if !val.IsRequired || key == fCmd.valueWithoutFlag {
+			continue
+		}
+
+		if fileFlag := fCmd.inFileFlag(key); fileFlag != "" {
+			inFileFlags[fileFlag] += "-" + key + " " + fCmd.flagTypes[key].placeholder(key) + " "
+		} else {
+			flags += "-" + key + " " + fCmd.flagTypes[key].placeholder(key) + " "
 		}
 	}
 
+	// flags which can be set in file are grouped, e.g. (-name <NAME> or in -f file)
+	for fileFlag, fileFlags := range inFileFlags {
+		flags += "(" + fileFlags + "or in -" + fileFlag + " file) "
+	}
+
 	requiredFlagC.Print(flags)
 	commandDescriptionC.Println("- " + fCmd.description)
 }
@@ -346,9 +499,14 @@ func (fCmd *FlaggedCommand) isAnyFlagRequired() bool {
 
 func (fCmd *FlaggedCommand) isAnyRequiredNotSet() bool {
 
-	for _, val := range fCmd.flagMap {
+	for key, val := range fCmd.flagMap {
 		if val.IsRequired && *val.Value == "" {
 
+			// value can be set in passed file, it is checked by command action
+			if fileFlag := fCmd.inFileFlag(key); fileFlag != "" && *fCmd.flagMap[fileFlag].Value != "" {
+				continue
+			}
+
 			return true
 		}
 	}
@@ -421,8 +579,103 @@ func GetAdditionalFlags(argv []string, nameDesc map[string]string) ([]string, ma
 	return retArgv, parsedArgs
 }
 
-// calls command maching to os.Args
-func (cmdHndl *CommandHandler) ParseArgs(argv []string) {
+// Get index of command word in command line arguments (argv[0] is program name): the first argument which is
+// neither flag nor flag value. Additional flags take value (unless it is given after "="), switches do not.
+// Length of argv is returned when there is no command word.
+func CommandWordIndex(argv []string, nameDesc map[string]string, switches ...string) int {
+
+	isSwitch := map[string]bool{}
+	for _, name := range switches {
+		isSwitch[name] = true
+	}
+
+	for idx := 1; idx < len(argv); idx++ {
+
+		arg := argv[idx]
+		if !strings.HasPrefix(arg, "-") {
+
+			return idx
+		}
+
+		name := flagArgName(arg)
+		if _, ok := nameDesc[name]; ok && !isSwitch[name] && !strings.Contains(arg, "=") {
+			idx++
+		}
+	}
+
+	return len(argv)
+}
+
+// Get all values of repeatable additional flag (-name value, -name=value, --name value or --name=value), flag
+// occurrences are removed from command line arguments
+func GetRepeatedFlag(argv []string, name string) ([]string, []string) {
+
+	retArgv := []string{}
+	values := []string{}
+
+	for idx := 0; idx < len(argv); idx++ {
+
+		arg := argv[idx]
+
+		if arg == "-"+name || arg == "--"+name {
+
+			if idx+1 < len(argv) {
+
+				values = append(values, argv[idx+1])
+				idx++
+			}
+			continue
+		}
+
+		if strings.HasPrefix(arg, "-"+name+"=") || strings.HasPrefix(arg, "--"+name+"=") {
+
+			values = append(values, arg[strings.Index(arg, "=")+1:])
+			continue
+		}
+
+		retArgv = append(retArgv, arg)
+	}
+
+	return retArgv, values
+}
+
+// Get value of switch additional flag (-name, -name=true or -name=false), flag occurrences are removed from
+// command line arguments. Switch is not set when flag was not passed.
+func GetSwitchFlag(argv []string, name string) ([]string, bool, bool, error) {
+
+	retArgv := []string{}
+	value := false
+	isSet := false
+
+	for _, arg := range argv {
+
+		flagName := strings.TrimPrefix(strings.TrimPrefix(arg, "-"), "-")
+		if !strings.HasPrefix(arg, "-") || (flagName != name && !strings.HasPrefix(flagName, name+"=")) {
+
+			retArgv = append(retArgv, arg)
+			continue
+		}
+
+		isSet = true
+		value = true
+
+		if eqIdx := strings.Index(flagName, "="); eqIdx >= 0 {
+
+			parsed, err := strconv.ParseBool(flagName[eqIdx+1:])
+			if err != nil {
+
+				return argv, false, false, NewUsageError("Invalid value of -" + name + ": " + flagName[eqIdx+1:])
+			}
+
+			value = parsed
+		}
+	}
+
+	return retArgv, value, isSet, nil
+}
+
+// calls command maching to os.Args, returns error of command (help request is not an error)
+func (cmdHndl *CommandHandler) ParseArgs(argv []string) error {
 
 	depth := 1
 
@@ -430,25 +683,31 @@ func (cmdHndl *CommandHandler) ParseArgs(argv []string) {
 		if argv[depth] == "add_flags" {
 
 			cmdHndl.printAdditionalFlags()
-			return
+			return nil
 		}
 	}
 
 	for _, cmd := range cmdHndl.cmds {
 
-		if res, _ := cmd.checkAndParse(argv, depth); res {
+		if res, err := cmd.checkAndParse(argv, depth); res {
+
+			if err == ErrHelp {
 
-			return
+				return nil
+			}
+
+			return err
 		}
 	}
 
 	if 0 == len(cmdHndl.cmds) {
 
-		fmt.Println("Error - program has no commands")
-		return
+		return errors.New("Error - program has no commands")
 	}
 
 	cmdHndl.printCommands()
+
+	return NewUsageError("Cannot match any command")
 }
 
 func (cmdHndl *CommandHandler) printAdditionalFlags() {
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 
@@ -12,9 +13,11 @@ type CommandActionMock struct {
 	mock.Mock
 }
 
-func (m *CommandActionMock) action() {
+func (m *CommandActionMock) action() error {
 
-	m.Called()
+	args := m.Called()
+
+	return args.Error(0)
 }
 
 func TestCommand(t *testing.T) {
@@ -38,7 +41,7 @@ func TestCommand(t *testing.T) {
 
 			mock := new(CommandActionMock)
 
-			mock.On("action").Return()
+			mock.On("action").Return(nil)
 			cmd := NewCommand(testEl.cmdName, mock.action, "")
 
 			res, _ := cmd.checkAndParse(testEl.input, 1)
@@ -61,9 +64,11 @@ type FlaggedActionMock struct {
 	mock.Mock
 }
 
-func (m *FlaggedActionMock) action(flagMap FlagMap) {
+func (m *FlaggedActionMock) action(flagMap FlagMap) error {
+
+	args := m.Called(flagMap)
 
-	m.Called(flagMap)
+	return args.Error(0)
 }
 
 func TestFlaggedCommand(t *testing.T) {
@@ -95,7 +100,7 @@ func TestFlaggedCommand(t *testing.T) {
 		t.Run(testEl.name, func(t *testing.T) {
 
 			mockFact := new(FlaggedActionMock)
-			mockFact.On("action", mock.Anything).Return()
+			mockFact.On("action", mock.Anything).Return(nil)
 			cmd := NewFlaggedCommand(testEl.cmdName, testEl.valWoutFlag, mockFact.action, testEl.flags, "")
 
 			res, _ := cmd.checkAndParse(testEl.input, 1)
@@ -117,6 +122,52 @@ func TestFlaggedCommand(t *testing.T) {
 	}
 }
 
+func TestFlaggedCommandSwitches(t *testing.T) {
+
+	var testVector = []struct {
+		name   string
+		input  []string
+		values map[string]string
+	}{
+		{"switch at the end", []string{"program_name", "fcommand", "-fflag", "value", "-switch"},
+			map[string]string{"fflag": "value", "switch": "true"}},
+		{"switch followed by flag", []string{"program_name", "fcommand", "-switch", "--fflag", "value"},
+			map[string]string{"fflag": "value", "switch": "true"}},
+		{"switch with value", []string{"program_name", "fcommand", "-fflag", "value", "-switch=false"},
+			map[string]string{"fflag": "value", "switch": "false"}},
+		{"value starting with dash", []string{"program_name", "fcommand", "-fflag", "-10", "-switch", "true"},
+			map[string]string{"fflag": "-10", "switch": "true"}},
+		{"flag without value followed by flag", []string{"program_name", "fcommand", "-fflag", "-switch"}, nil},
+		{"flag without value at the end", []string{"program_name", "fcommand", "-switch", "-fflag"}, nil},
+	}
+
+	for _, testEl := range testVector {
+		t.Run(testEl.name, func(t *testing.T) {
+
+			values := map[string]string{}
+			cmd := NewFlaggedCommand("fcommand", "", func(flagMap FlagMap) error {
+				for key, val := range flagMap {
+					values[key] = *val.Value
+				}
+				return nil
+			}, map[string]bool{"fflag": true, "switch": false}, "").SetFlagTypes(FlagTypes{"switch": {Type: BoolFlag}})
+
+			res, err := cmd.checkAndParse(testEl.input, 1)
+			assert.True(t, res)
+
+			if testEl.values == nil {
+
+				assert.IsType(t, &UsageError{}, err)
+				assert.Equal(t, map[string]string{}, values)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, testEl.values, values)
+		})
+	}
+}
+
 func TestSubCommand(t *testing.T) {
 
 	var testVector = []struct {
@@ -150,6 +201,135 @@ func TestSubCommand(t *testing.T) {
 	}
 }
 
+func TestSubCommandWithDefault(t *testing.T) {
+
+	var testVector = []struct {
+		name          string
+		input         []string
+		commandCalls  int
+		defaultCalls  int
+		defaultValue  string
+		expectedError bool
+	}{
+		{"call next command", []string{"program_name", "scommand", "command"}, 1, 0, "", false},
+		{"call default with flag", []string{"program_name", "scommand", "-value", "val"}, 0, 1, "val", false},
+		{"call default with value", []string{"program_name", "scommand", "val"}, 0, 1, "val", false},
+		{"default without required flag", []string{"program_name", "scommand"}, 0, 0, "", true},
+	}
+
+	for _, testEl := range testVector {
+		t.Run(testEl.name, func(t *testing.T) {
+
+			commandMock := new(CommandActionMock)
+			commandMock.On("action").Return(nil)
+
+			defaultMock := new(FlaggedActionMock)
+			defaultMock.On("action", mock.Anything).Return(nil)
+
+			defaultCmd := NewFlaggedCommand("scommand", "value", defaultMock.action, map[string]bool{}, "")
+			cmd := NewSubCommandWithDefault("scommand",
+				[]CommandBaseInterface{NewCommand("command", commandMock.action, "")}, defaultCmd, "")
+
+			res, err := cmd.checkAndParse(testEl.input, 1)
+			assert.True(t, res)
+			assert.Equal(t, testEl.expectedError, err != nil)
+
+			commandMock.AssertNumberOfCalls(t, "action", testEl.commandCalls)
+			defaultMock.AssertNumberOfCalls(t, "action", testEl.defaultCalls)
+
+			if testEl.defaultCalls > 0 {
+				assert.Equal(t, testEl.defaultValue, *defaultCmd.flagMap["value"].Value)
+			}
+
+			cmd.printDescription()
+		})
+	}
+}
+
+func TestRepeatedFlag(t *testing.T) {
+
+	var testVector = []struct {
+		name       string
+		input      []string
+		argvOutput []string
+		values     []string
+	}{
+		{"no flag", []string{"program_name", "command"}, []string{"program_name", "command"}, []string{}},
+		{"flags with values", []string{"program_name", "-H", "A: 1", "command", "--H=B: 2", "-H", "C: 3"},
+			[]string{"program_name", "command"}, []string{"A: 1", "B: 2", "C: 3"}},
+		{"flag without value", []string{"program_name", "command", "-H"}, []string{"program_name", "command"},
+			[]string{}},
+		{"other flags", []string{"program_name", "command", "-Header", "x", "-H=A: 1"},
+			[]string{"program_name", "command", "-Header", "x"}, []string{"A: 1"}},
+	}
+
+	for _, testEl := range testVector {
+		t.Run(testEl.name, func(t *testing.T) {
+
+			argv, values := GetRepeatedFlag(testEl.input, "H")
+			assert.Equal(t, testEl.argvOutput, argv)
+			assert.Equal(t, testEl.values, values)
+		})
+	}
+}
+
+func TestSwitchFlag(t *testing.T) {
+
+	var testVector = []struct {
+		name       string
+		input      []string
+		argvOutput []string
+		value      bool
+		isSet      bool
+		valid      bool
+	}{
+		{"no flag", []string{"program_name", "command"}, []string{"program_name", "command"}, false, false, true},
+		{"flag", []string{"program_name", "-insecure", "command"}, []string{"program_name", "command"}, true, true,
+			true},
+		{"flag with value", []string{"program_name", "command", "--insecure=false"}, []string{"program_name",
+			"command"}, false, true, true},
+		{"other flag", []string{"program_name", "command", "-insecure_mode", "x"}, []string{"program_name",
+			"command", "-insecure_mode", "x"}, false, false, true},
+		{"invalid value", []string{"program_name", "-insecure=maybe"}, []string{"program_name", "-insecure=maybe"},
+			false, false, false},
+	}
+
+	for _, testEl := range testVector {
+		t.Run(testEl.name, func(t *testing.T) {
+
+			argv, value, isSet, err := GetSwitchFlag(testEl.input, "insecure")
+			assert.Equal(t, testEl.argvOutput, argv)
+			assert.Equal(t, testEl.value, value)
+			assert.Equal(t, testEl.isSet, isSet)
+			assert.Equal(t, testEl.valid, err == nil)
+		})
+	}
+}
+
+func TestCommandWordIndex(t *testing.T) {
+
+	flags := map[string]string{"profile": "", "H": "", "debug": ""}
+
+	var testVector = []struct {
+		name  string
+		input []string
+		index int
+	}{
+		{"no flags", []string{"program_name", "flip", "-timeout", "5m"}, 1},
+		{"flags with values", []string{"program_name", "-profile", "flip", "-H=A: 1", "flip", "videos"}, 4},
+		{"switch", []string{"program_name", "-debug", "flip", "videos"}, 2},
+		{"unknown flag", []string{"program_name", "-timeout", "flip"}, 2},
+		{"no command", []string{"program_name", "-profile", "flip"}, 3},
+	}
+
+	for _, testEl := range testVector {
+		t.Run(testEl.name, func(t *testing.T) {
+
+			assert.Equal(t, testEl.index, CommandWordIndex(testEl.input, flags, "debug"))
+		})
+	}
+}
+
 func TestAdditionalFlags(t *testing.T) {
 
 	var testVector = []struct {
@@ -234,3 +414,46 @@ func TestCommandHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestCommandHandlerErrors(t *testing.T) {
+
+	actionErr := errors.New("action error")
+	commands := []CommandBaseInterface{
+		NewCommand("command", func() error { return nil }, ""),
+		NewCommand("failing", func() error { return actionErr }, ""),
+		NewFlaggedCommand("fcommand", "", func(flagMap FlagMap) error { return nil },
+			map[string]bool{"fflag": true}, ""),
+	}
+
+	var testVector = []struct {
+		name  string
+		input []string
+		err   error
+		usage bool
+	}{
+		{"command", []string{"program_name", "command"}, nil, false},
+		{"action error", []string{"program_name", "failing"}, actionErr, false},
+		{"flagged command", []string{"program_name", "fcommand", "-fflag", "value"}, nil, false},
+		{"help", []string{"program_name", "fcommand", "help"}, nil, false},
+		{"help flag", []string{"program_name", "fcommand", "-fflag", "value", "-help"}, nil, false},
+		{"unknown command", []string{"program_name", "unknown"}, nil, true},
+		{"missing flag", []string{"program_name", "fcommand", "-other", "value"}, nil, true},
+		{"unknown flag", []string{"program_name", "fcommand", "-fflag", "value", "-other", "value"}, nil, true},
+	}
+
+	for _, testEl := range testVector {
+
+		t.Run(testEl.name, func(t *testing.T) {
+
+			err := NewCommandHandler("program_name", commands, map[string]string{}).ParseArgs(testEl.input)
+
+			if testEl.usage {
+
+				assert.True(t, IsUsageError(err))
+			} else {
+
+				assert.Equal(t, testEl.err, err)
+			}
+		})
+	}
+}
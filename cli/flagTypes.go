@@ -0,0 +1,245 @@
+package cli
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FlagType - type of flag value, values of typed flags are validated before command action is called
+type FlagType int
+
+const (
+	StringFlag FlagType = iota
+	IntFlag
+	FloatFlag
+	BoolFlag
+	DurationFlag
+	EnumFlag
+	ListFlag
+)
+
+// FlagSpec - type of flag value, choices of enum flag and information if flag can be passed more than once.
+// InFile names flag of file which can hold value of flag (e.g. request body file), such required flag is not
+// checked when the file flag is passed.
+type FlagSpec struct {
+	Type     FlagType
+	Choices  []string
+	Repeated bool
+	InFile   string
+}
+
+// FlagTypes key - is flag name
+type FlagTypes map[string]FlagSpec
+
+// flagValue - flag.Value which keeps the last value (as string flag does) and all passed values
+type flagValue struct {
+	value  *string
+	values *[]string
+}
+
+func (val *flagValue) String() string {
+
+	if val.value == nil {
+
+		return ""
+	}
+
+	return *val.value
+}
+
+func (val *flagValue) Set(value string) error {
+
+	*val.value = value
+	*val.values = append(*val.values, value)
+
+	return nil
+}
+
+// Set types of command flags, types of flags which command does not have are ignored
+func (fCmd *FlaggedCommand) SetFlagTypes(types FlagTypes) *FlaggedCommand {
+
+	for key, spec := range types {
+
+		if _, ok := fCmd.flagMap[key]; ok {
+			fCmd.flagTypes[key] = spec
+		}
+	}
+
+	return fCmd
+}
+
+// Set types of flags of all flagged commands in given commands and their sub commands
+func SetFlagTypes(cmds []CommandBaseInterface, types FlagTypes) {
+
+	for _, cmd := range cmds {
+
+		switch command := cmd.(type) {
+
+		case *FlaggedCommand:
+			command.SetFlagTypes(types)
+
+		case *SubCommand:
+			SetFlagTypes(command.nextCommands, types)
+			if command.defaultCommand != nil {
+				SetFlagTypes([]CommandBaseInterface{command.defaultCommand}, types)
+			}
+		}
+	}
+}
+
+// Get flag of file which can hold value of given flag, empty when flag cannot be set in file of command
+func (fCmd *FlaggedCommand) inFileFlag(key string) string {
+
+	fileFlag := fCmd.flagTypes[key].InFile
+	if _, ok := fCmd.flagMap[fileFlag]; fileFlag == "" || !ok {
+
+		return ""
+	}
+
+	return fileFlag
+}
+
+// Validate values of typed flags, list flag values are split into elements
+func (fCmd *FlaggedCommand) validateFlags() error {
+
+	for key, props := range fCmd.flagMap {
+
+		spec := fCmd.flagTypes[key]
+
+		if props.Values == nil {
+			props.Values = &[]string{}
+		}
+
+		if len(*props.Values) == 0 {
+
+			if *props.Value == "" {
+				continue
+			}
+
+			// value passed without flag name
+			*props.Values = []string{*props.Value}
+		}
+
+		if !spec.Repeated && len(*props.Values) > 1 {
+
+			*props.Values = (*props.Values)[len(*props.Values)-1:]
+		}
+
+		if spec.Type == ListFlag {
+
+			elements := []string{}
+			for _, value := range *props.Values {
+				for _, element := range strings.Split(value, ",") {
+					if element = strings.TrimSpace(element); element != "" {
+						elements = append(elements, element)
+					}
+				}
+			}
+
+			*props.Values = elements
+		}
+
+		for _, value := range *props.Values {
+
+			if !spec.isValid(value) {
+
+				return NewUsageError("Invalid value of -" + key + ": " + value + " (" + spec.typeName() +
+					" expected)")
+			}
+		}
+
+		fCmd.flagMap[key] = props
+	}
+
+	return nil
+}
+
+// Check if value can be parsed as flag type
+func (spec FlagSpec) isValid(value string) bool {
+
+	var err error
+
+	switch spec.Type {
+
+	case IntFlag:
+		_, err = strconv.ParseInt(value, 10, 64)
+
+	case FloatFlag:
+		_, err = strconv.ParseFloat(value, 64)
+
+	case BoolFlag:
+		_, err = strconv.ParseBool(value)
+
+	case DurationFlag:
+		if seconds, atoiErr := strconv.Atoi(value); atoiErr == nil {
+			return seconds >= 0
+		}
+
+		var duration time.Duration
+		duration, err = time.ParseDuration(value)
+		if err == nil && duration < 0 {
+			return false
+		}
+
+	case EnumFlag:
+		for _, choice := range spec.Choices {
+			if value == choice {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return err == nil
+}
+
+func (spec FlagSpec) typeName() string {
+
+	switch spec.Type {
+
+	case IntFlag:
+		return "int"
+
+	case FloatFlag:
+		return "float"
+
+	case BoolFlag:
+		return "bool"
+
+	case DurationFlag:
+		return "duration"
+
+	case EnumFlag:
+		return strings.Join(spec.Choices, "|")
+
+	case ListFlag:
+		return "list"
+	}
+
+	return "string"
+}
+
+// Get placeholder of flag value printed in help, e.g. <FACTORY_ID>, <int>, <h264|webm>
+func (spec FlagSpec) placeholder(key string) string {
+
+	placeholder := "<" + strings.ToUpper(key) + ">"
+
+	switch spec.Type {
+
+	case StringFlag:
+
+	case ListFlag:
+		placeholder = "<" + strings.ToUpper(key) + ",...>"
+
+	default:
+		placeholder = "<" + spec.typeName() + ">"
+	}
+
+	if spec.Repeated {
+		placeholder += "..."
+	}
+
+	return placeholder
+}
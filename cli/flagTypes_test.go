@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypedFlags(t *testing.T) {
+
+	types := FlagTypes{"count": {Type: IntFlag}, "rate": {Type: FloatFlag}, "on": {Type: BoolFlag},
+		"wait": {Type: DurationFlag}, "codec": {Type: EnumFlag, Choices: []string{"h264", "webm"}},
+		"tags": {Type: ListFlag}, "label": {Type: StringFlag, Repeated: true}}
+
+	var testVector = []struct {
+		name       string
+		input      []string
+		actionDone bool
+		values     map[string][]string
+	}{
+		{"no typed flag", []string{"program_name", "fcommand"}, true, map[string][]string{}},
+		{"valid values", []string{"program_name", "fcommand", "-count", "10", "-rate", "29.97", "-on=false",
+			"-wait", "1m30s", "-codec", "webm"}, true, map[string][]string{"count": {"10"}, "rate": {"29.97"},
+			"on": {"false"}, "wait": {"1m30s"}, "codec": {"webm"}}},
+		{"duration in seconds", []string{"program_name", "fcommand", "-wait", "90"}, true,
+			map[string][]string{"wait": {"90"}}},
+		{"invalid int", []string{"program_name", "fcommand", "-count", "ten"}, false, nil},
+		{"invalid float", []string{"program_name", "fcommand", "-rate", "fast"}, false, nil},
+		{"invalid bool", []string{"program_name", "fcommand", "-on", "maybe"}, false, nil},
+		{"negative duration", []string{"program_name", "fcommand", "-wait", "-5s"}, false, nil},
+		{"unknown choice", []string{"program_name", "fcommand", "-codec", "vp9"}, false, nil},
+		{"list", []string{"program_name", "fcommand", "-tags", "a, b,,c", "-tags", "d"}, true,
+			map[string][]string{"tags": {"d"}}},
+		{"list in one flag", []string{"program_name", "fcommand", "-tags", "a, b,,c"}, true,
+			map[string][]string{"tags": {"a", "b", "c"}}},
+		{"repeated flag", []string{"program_name", "fcommand", "-label", "x", "-label", "y"}, true,
+			map[string][]string{"label": {"x", "y"}}},
+		{"not repeated flag keeps last value", []string{"program_name", "fcommand", "-count", "ten", "-count", "1"},
+			true, map[string][]string{"count": {"1"}}},
+	}
+
+	for _, testEl := range testVector {
+		t.Run(testEl.name, func(t *testing.T) {
+
+			actionDone := false
+			values := map[string][]string{}
+			cmd := NewFlaggedCommand("fcommand", "", func(flagMap FlagMap) error {
+
+				actionDone = true
+				for key, val := range flagMap {
+					if len(*val.Values) > 0 {
+						values[key] = *val.Values
+					}
+				}
+				return nil
+			}, map[string]bool{"count": false, "rate": false, "on": false, "wait": false, "codec": false,
+				"tags": false, "label": false}, "").SetFlagTypes(types)
+
+			res, err := cmd.checkAndParse(testEl.input, 1)
+			assert.True(t, res)
+			assert.Equal(t, testEl.actionDone, actionDone)
+
+			if testEl.actionDone {
+
+				assert.Nil(t, err)
+				assert.Equal(t, testEl.values, values)
+			} else {
+
+				assert.IsType(t, &UsageError{}, err)
+			}
+		})
+	}
+}
+
+func TestSetFlagTypes(t *testing.T) {
+
+	cmd := NewFlaggedCommand("fcommand", "", func(flagMap FlagMap) error { return nil },
+		map[string]bool{"count": false}, "")
+	sub := NewSubCommand("sub", []CommandBaseInterface{cmd}, "")
+
+	SetFlagTypes([]CommandBaseInterface{sub}, FlagTypes{"count": {Type: IntFlag}, "other": {Type: BoolFlag}})
+
+	assert.Equal(t, FlagTypes{"count": {Type: IntFlag}}, cmd.flagTypes)
+}
+
+func TestInFileFlags(t *testing.T) {
+
+	var testVector = []struct {
+		name       string
+		input      []string
+		actionDone bool
+	}{
+		{"required flag", []string{"program_name", "fcommand", "-name", "web"}, true},
+		{"file flag", []string{"program_name", "fcommand", "-f", "body.yaml"}, true},
+		{"neither", []string{"program_name", "fcommand", "-other", "x"}, false},
+	}
+
+	for _, testEl := range testVector {
+		t.Run(testEl.name, func(t *testing.T) {
+
+			actionDone := false
+			cmd := NewFlaggedCommand("fcommand", "", func(flagMap FlagMap) error {
+
+				actionDone = true
+				return nil
+			}, map[string]bool{"name": true, "f": false, "other": false}, "").SetFlagTypes(
+				FlagTypes{"name": {InFile: "f"}})
+
+			res, err := cmd.checkAndParse(testEl.input, 1)
+			assert.True(t, res)
+			assert.Equal(t, testEl.actionDone, actionDone)
+			assert.Equal(t, testEl.actionDone, err == nil)
+		})
+	}
+
+	// command without file flag requires flag
+	cmd := NewFlaggedCommand("fcommand", "", func(flagMap FlagMap) error { return nil },
+		map[string]bool{"name": true}, "").SetFlagTypes(FlagTypes{"name": {InFile: "f"}})
+
+	assert.Equal(t, "", cmd.inFileFlag("name"))
+	assert.True(t, cmd.isAnyRequiredNotSet())
+}
+
+func TestFlagPlaceholder(t *testing.T) {
+
+	var testVector = []struct {
+		spec        FlagSpec
+		placeholder string
+	}{
+		{FlagSpec{}, "<FACTORY_ID>"},
+		{FlagSpec{Type: IntFlag}, "<int>"},
+		{FlagSpec{Type: DurationFlag}, "<duration>"},
+		{FlagSpec{Type: EnumFlag, Choices: []string{"h264", "webm"}}, "<h264|webm>"},
+		{FlagSpec{Type: ListFlag}, "<FACTORY_ID,...>"},
+		{FlagSpec{Repeated: true}, "<FACTORY_ID>..."},
+	}
+
+	for _, testEl := range testVector {
+
+		assert.Equal(t, testEl.placeholder, testEl.spec.placeholder("factory_id"))
+	}
+}
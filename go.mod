@@ -16,4 +16,5 @@ require (
 	golang.org/x/net v0.0.0-20190606173856-1492cefac77f // indirect
 	golang.org/x/oauth2 v0.0.0-20190604053449-0f29369cfe45
 	gopkg.in/ini.v1 v1.42.0
+	gopkg.in/yaml.v2 v2.2.2
 )
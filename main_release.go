@@ -1,6 +1,8 @@
 package main
 
+import "os"
+
 func main() {
 
-	createTcsCli()
+	os.Exit(createTcsCli())
 }
@@ -2,14 +2,13 @@ package main
 
 import (
 	"fmt"
+	"net/http"
 	"os"
+	"strings"
 	"tcs-cli/cli"
 	"tcs-cli/telestream"
-	"gopkg.in/ini.v1"
 )
 
-const configFileName = ".tcs-credentials"
-
 func createTtsProjectsCommands(client *telestream.TtsClient) []cli.CommandBaseInterface {
 
 	// projects list command
@@ -49,6 +48,10 @@ func createTtsJobsCommands(client *telestream.TtsClient) []cli.CommandBaseInterf
 	jobsDescribeCmd := cli.NewFlaggedCommand("describe", "job_id", client.DescribeJob,
 		client.GetDescribeJobProperties(), "Describe job")
 
+	// jobs wait command
+	jobsWaitCmd := cli.NewFlaggedCommand("wait", "job_id", client.WaitJob,
+		client.GetWaitJobProperties(), "Wait until job is finished (-timeout e.g. 30m)")
+
 	// jobs delete command
 	jobsDeleteCmd := cli.NewFlaggedCommand("delete", "job_id", client.DeleteJob,
 		client.GetDeleteJobProperties(), "Delete job")
@@ -61,7 +64,7 @@ func createTtsJobsCommands(client *telestream.TtsClient) []cli.CommandBaseInterf
 	jobsJobOutputsCmd := cli.NewFlaggedCommand("outputs", "job_id", client.JobOutputs,
 		client.GetJobOutputsProperties(), "Describe job outputs")
 
-	return []cli.CommandBaseInterface{jobsListCmd, jobsCreateCmd, jobsDescribeCmd, jobsDeleteCmd,
+	return []cli.CommandBaseInterface{jobsListCmd, jobsCreateCmd, jobsDescribeCmd, jobsWaitCmd, jobsDeleteCmd,
 		jobsJobResultCmd, jobsJobOutputsCmd}
 }
 
@@ -105,7 +108,10 @@ func createTtsCommands(client *telestream.TtsClient) []cli.CommandBaseInterface
 	flipCmd := cli.NewSubCommand("tts", []cli.CommandBaseInterface{projectsCmd, jobsCmd, corporaCmd},
 		"manage your tts service")
 
-	return []cli.CommandBaseInterface{flipCmd}
+	ttsCmds := []cli.CommandBaseInterface{flipCmd}
+	cli.SetFlagTypes(ttsCmds, client.GetFlagTypes())
+
+	return ttsCmds
 }
 
 func createFactoriesCommands(client *telestream.FlipClient) []cli.CommandBaseInterface {
@@ -161,6 +167,14 @@ func createVideosCommands(client *telestream.FlipClient) []cli.CommandBaseInterf
 	videosCreateCmd := cli.NewFlaggedCommand("create", "", client.CreateVideo,
 		client.GetCreateVideoProperties(), "creates video")
 
+	// videos upload command
+	videosUploadCmd := cli.NewFlaggedCommand("upload", "", client.UploadVideo,
+		client.GetUploadVideoProperties(), "uploads local video file")
+
+	// videos wait command
+	videosWaitCmd := cli.NewFlaggedCommand("wait", "video_id", client.WaitVideo,
+		client.GetWaitVideoProperties(), "waits until video is processed (-timeout e.g. 30m)")
+
 	// videos cancel command
 	videosCancelCmd := cli.NewFlaggedCommand("cancel", "video_id", client.CancelVideo,
 		client.GetCancelVideoProperties(), "cancels video")
@@ -169,8 +183,8 @@ func createVideosCommands(client *telestream.FlipClient) []cli.CommandBaseInterf
 	videosDeleteCmd := cli.NewFlaggedCommand("delete", "video_id", client.DeleteVideo,
 		client.GetDeleteVideoProperties(), "deletes video")
 
-	return []cli.CommandBaseInterface{videosListCmd, videosDescribeCmd, videosCreateCmd,
-		videosCancelCmd, videosDeleteCmd}
+	return []cli.CommandBaseInterface{videosListCmd, videosDescribeCmd, videosCreateCmd, videosUploadCmd,
+		videosWaitCmd, videosCancelCmd, videosDeleteCmd}
 }
 
 func createEncodingsCommands(client *telestream.FlipClient) []cli.CommandBaseInterface {
@@ -183,6 +197,14 @@ func createEncodingsCommands(client *telestream.FlipClient) []cli.CommandBaseInt
 	encodingsDescribeCmd := cli.NewFlaggedCommand("describe", "encoding_id", client.DescribeEncoding,
 		client.GetDescribeEncodingProperties(), "describes encoding by factory_id and its id")
 
+	// encodings wait command
+	waitDescribeCmd := cli.NewFlaggedCommand("wait", "encoding_id", client.WaitEncoding,
+		client.GetWaitEncodingProperties(), "waits until encoding is finished (-timeout e.g. 30m)")
+
+	// encodings watch command
+	watchDescribeCmd := cli.NewFlaggedCommand("watch", "", client.WatchEncodings,
+		client.GetWatchEncodingsProperties(), "watches progress of encodings by factory_id (and video_id)")
+
 	// encodings cancel command
 	cancelDescribeCmd := cli.NewFlaggedCommand("cancel", "encoding_id", client.CancelEncoding,
 		client.GetCancelEncodingProperties(), "cancels encoding by factory_id and encoding id")
@@ -195,8 +217,8 @@ func createEncodingsCommands(client *telestream.FlipClient) []cli.CommandBaseInt
 	deleteDescribeCmd := cli.NewFlaggedCommand("delete", "encoding_id", client.DeleteEncoding,
 		client.GetDeleteEncodingProperties(), "deletes encoding by factory_id and encoding id")
 
-	return []cli.CommandBaseInterface{encodingsListCmd, encodingsDescribeCmd, cancelDescribeCmd,
-		signedUrlsDescribeCmd, deleteDescribeCmd}
+	return []cli.CommandBaseInterface{encodingsListCmd, encodingsDescribeCmd, waitDescribeCmd,
+		watchDescribeCmd, cancelDescribeCmd, signedUrlsDescribeCmd, deleteDescribeCmd}
 }
 
 func createFlipCommands(client *telestream.FlipClient) []cli.CommandBaseInterface {
@@ -221,98 +243,311 @@ func createFlipCommands(client *telestream.FlipClient) []cli.CommandBaseInterfac
 	flipCmd := cli.NewSubCommand("flip", []cli.CommandBaseInterface{factoriesCmd, profilesCmd, videosCmd, encodingsCmd},
 		"manage your flip service")
 
-	return []cli.CommandBaseInterface{flipCmd}
+	flipCmds := []cli.CommandBaseInterface{flipCmd}
+	cli.SetFlagTypes(flipCmds, client.GetFlagTypes())
+
+	return flipCmds
+}
+
+func createConfigureCommands(client *telestream.ConfigClient) *cli.SubCommand {
+
+	// configure list command
+	configureListCmd := cli.NewCommand("list", client.ListProfiles, "lists profiles of configuration file")
+
+	// configure show command
+	configureShowCmd := cli.NewCommand("show", client.ShowCredentials,
+		"shows source of used credentials and masked api key")
+
+	// configure remove command
+	configureRemoveCmd := cli.NewFlaggedCommand("remove", "name", client.RemoveProfile,
+		client.GetRemoveProfileProperties(), "removes profile from configuration file")
+
+	// configure command called with flags only
+	configureCmd := cli.NewFlaggedCommand("configure", "api_key", client.Configure, client.GetConfigureProperties(),
+		"create configuration file for tsc command line tool with credentials that are used to interact with telestream cloud API").
+		SetFlagTypes(client.GetConfigureFlagTypes())
+
+	return cli.NewSubCommandWithDefault("configure", []cli.CommandBaseInterface{configureListCmd, configureShowCmd,
+		configureRemoveCmd},
+		configureCmd, "create configuration file for tsc command line tool with credentials that are used to "+
+			"interact with telestream cloud API (-profile selects profile)")
 }
 
-func createConfig(argsMap cli.FlagMap) {
+// Run tcs command given by os.Args, returns process exit code
+func createTcsCli() int {
 
-	key := *argsMap["api_key"].Value
+	configureCmdStr := "configure"
 
-	var home string
-	var err error
+	argvOutput := os.Args
 
-	if home, err = os.UserHomeDir(); err != nil {
+	var flags map[string]*string
 
-		fmt.Println("Cannot get home directory: ", err.Error())
-		return
+	additionalFlags := map[string]string{"header_key": "additive http header key",
+		"header_val":           "additive http header value",
+		"output":               "output format: table (default), json, yaml or csv",
+		"columns":              "comma separated json names of printed fields",
+		"template":             "go template executed for each printed row or object",
+		"query":                "query applied on service response before printing (e.g. status, videos[*].id)",
+		"profile":              "profile of configuration file (default: TCS_PROFILE environment variable or default)",
+		"api_key":              "api key used instead of TCS_API_KEY environment variable and configuration file",
+		"endpoint":             "base url of service API used instead of profile flip_endpoint or tts_endpoint",
+		"H":                    "additional http header \"Key: Value\", can be repeated",
+		"timeout":              "timeout of single API request given before command (e.g. 30s, 2m; default 60s of waiting for response, 0 turns it off)",
+		"proxy":                "http proxy url used instead of HTTPS_PROXY and HTTP_PROXY environment variables",
+		"ca_cert":              "PEM file with CA certificates trusted in addition to system ones",
+		"client_cert":          "PEM file with client certificate (used with -client_key)",
+		"client_key":           "PEM file with private key of client certificate",
+		"insecure_skip_verify": "switch which disables verification of server certificate",
+		"retries":              "number of retries of request failed with network error or 429, 5xx status (default 3)",
+		"retry_max_wait":       "max delay between retries of failed request (default 30s)",
+		"rps":                  "max number of API requests per second (e.g. 5, 0.5), not limited by default",
+		"v":                    "switch which logs API requests with response status and latency",
+		"debug":                "switch which logs API requests and responses with headers and bodies and retries",
+		"log_file":             "file which API requests are appended to instead of stderr (logs requests without -v)"}
+
+	// -timeout after command word belongs to command (e.g. wait commands) and so does -api_key of configure
+	// command, such global flags are taken only before command word
+	cmdIdx := cli.CommandWordIndex(os.Args, additionalFlags, "insecure_skip_verify", "v", "debug")
+	isConfigure := cmdIdx < len(os.Args) && os.Args[cmdIdx] == configureCmdStr
+
+	leadingFlags := map[string]bool{"timeout": true}
+	if isConfigure {
+		leadingFlags["api_key"] = true
 	}
 
-	configFilePath := home + "/" + configFileName
-	os.Remove(configFilePath)
+	leadingArgv := append([]string{}, os.Args[:cmdIdx]...)
+	leadingValues := map[string]string{}
 
-	cfg := ini.Empty()
-	cfg.Section("default").Key("api_key").SetValue(key)
+	for name := range leadingFlags {
 
-	if _, err = os.Create(configFilePath); err != nil {
+		var values []string
+		if leadingArgv, values = cli.GetRepeatedFlag(leadingArgv, name); len(values) > 0 {
+			leadingValues[name] = values[len(values)-1]
+		}
+	}
 
-		fmt.Println("Cannot create configuration file: ", err.Error())
-		return
+	globalFlags := map[string]string{}
+	for key, val := range additionalFlags {
+		if !leadingFlags[key] {
+			globalFlags[key] = val
+		}
 	}
 
-	if err = cfg.SaveTo(configFilePath); err != nil {
+	argvOutput, headerValues := cli.GetRepeatedFlag(append(leadingArgv, os.Args[cmdIdx:]...), "H")
 
-		fmt.Println("Cannot save configuration file: ", err.Error())
-		return
+	argvOutput, insecureSkipVerify, insecureSkipVerifySet, err := cli.GetSwitchFlag(argvOutput, "insecure_skip_verify")
+	if err != nil {
+
+		fmt.Fprintln(os.Stderr, err.Error())
+		return telestream.ExitUsage
 	}
 
-	fmt.Println("Credentials saved")
-}
+	argvOutput, verbose, _, err := cli.GetSwitchFlag(argvOutput, "v")
+	if err != nil {
+
+		fmt.Fprintln(os.Stderr, err.Error())
+		return telestream.ExitUsage
+	}
+
+	argvOutput, debug, _, err := cli.GetSwitchFlag(argvOutput, "debug")
+	if err != nil {
+
+		fmt.Fprintln(os.Stderr, err.Error())
+		return telestream.ExitUsage
+	}
+
+	argvOutput, flags = cli.GetAdditionalFlags(argvOutput, globalFlags)
+
+	logLevel := telestream.LogOff
+	if debug {
+		logLevel = telestream.LogDebug
+	} else if verbose || *flags["log_file"] != "" {
+		logLevel = telestream.LogVerbose
+	}
+
+	if logLevel != telestream.LogOff {
+
+		logFile := os.Stderr
+
+		if *flags["log_file"] != "" {
+
+			logFile, err = os.OpenFile(*flags["log_file"], os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+			if err != nil {
+
+				fmt.Fprintln(os.Stderr, "Cannot open log file: "+err.Error())
+				return telestream.ExitUsage
+			}
+			defer logFile.Close()
+		}
+
+		telestream.SetLogOutput(logFile, logLevel)
+		defer telestream.SetLogOutput(nil, telestream.LogOff)
+	}
+
+	flagApiKey := leadingValues["api_key"]
+	if isConfigure {
+
+		// -api_key after configure word is api key being stored, configure show shows it as well
+		if _, apiKeys := cli.GetRepeatedFlag(argvOutput, "api_key"); flagApiKey == "" && len(apiKeys) > 0 {
+			flagApiKey = apiKeys[len(apiKeys)-1]
+		}
+	} else {
+
+		flagApiKey = *flags["api_key"]
+	}
+
+	profile := telestream.GetProfileName(*flags["profile"])
 
-func readConfig() string {
+	configFilePath, pathErr := telestream.GetConfigFilePath()
 
-	var home string
-	var err error
+	if pathErr == nil {
+		if permErr := telestream.CheckConfigFilePermissions(configFilePath); permErr != nil {
 
-	if home, err = os.UserHomeDir(); err != nil {
+			fmt.Fprintln(os.Stderr, "Warning: "+permErr.Error())
+		}
+	}
+
+	// configure commands do not use api key, so it is not read from keyring or decrypted
+	credentials := telestream.Credentials{}
+
+	if len(argvOutput) > 1 && !isConfigure {
+
+		var credentialsErr error
+		credentials, credentialsErr = telestream.ResolveCredentials(flagApiKey, configFilePath, profile)
+
+		if credentials.ApiKey == "" {
+
+			if pathErr != nil {
+				fmt.Fprintln(os.Stderr, pathErr.Error())
+			} else if credentialsErr != nil {
+				fmt.Fprintln(os.Stderr, credentialsErr.Error())
+			}
 
-		fmt.Println("Cannot get home directory: ", err.Error())
-		return ""
+			fmt.Fprintln(os.Stderr, "Firstly you should configure credentials of profile "+profile+
+				" (or set "+telestream.ApiKeyEnv+" environment variable)")
+			return telestream.ExitAuth
+		}
 	}
 
-	cfg, err := ini.Load(home + "/" + configFileName)
+	settings, err := telestream.LoadProfileSettings(configFilePath, profile)
 	if err != nil {
 
-		return ""
+		fmt.Fprintln(os.Stderr, err.Error())
+		return telestream.ExitUsage
 	}
 
-	return cfg.Section("default").Key("api_key").String()
-}
+	if *flags["endpoint"] != "" {
 
-func createTcsCli() {
+		settings.FlipEndpoint = *flags["endpoint"]
+		settings.TtsEndpoint = *flags["endpoint"]
+	}
 
-	configureCmdStr := "configure"
+	// http client flags replace profile settings
+	for flagName, setting := range map[string]*string{"proxy": &settings.Proxy, "ca_cert": &settings.CaCert, "client_cert": &settings.ClientCert,
+		"client_key": &settings.ClientKey, "retries": &settings.Retries, "retry_max_wait": &settings.RetryMaxWait,
+		"rps": &settings.Rps} {
+
+		if *flags[flagName] != "" {
+			*setting = *flags[flagName]
+		}
+	}
 
-	apiKey := readConfig()
+	if timeout, ok := leadingValues["timeout"]; ok {
+		settings.Timeout = timeout
+	}
+
+	if insecureSkipVerifySet {
+		settings.InsecureSkipVerify = insecureSkipVerify
+	}
 
-	if apiKey == "" && len(os.Args) > 1 && os.Args[1] != configureCmdStr {
+	settings.ApiKey = credentials.ApiKey
 
-		fmt.Println("Firstly you should configure credentials")
-		return
+	httpClient, err := telestream.NewHttpClient(settings)
+	if err != nil {
+
+		fmt.Fprintln(os.Stderr, err.Error())
+		return telestream.ExitCode(err)
 	}
 
-	argvOutput := os.Args
+	headers, err := telestream.ParseHeaders(headerValues)
+	if err != nil {
 
-	var flags map[string]*string
+		fmt.Fprintln(os.Stderr, err.Error())
+		return telestream.ExitUsage
+	}
 
-	argvOutput, flags = cli.GetAdditionalFlags(os.Args, map[string]string{"header_key": "additive http header key",
-		"header_val": "additive http header val"})
+	// headers passed with -H replace profile headers, keys of both are compared in canonical form
+	for key, val := range settings.Headers {
+		if _, ok := headers[http.CanonicalHeaderKey(key)]; !ok {
+			headers[http.CanonicalHeaderKey(key)] = val
+		}
+	}
 
 	additionalHeaderKey := *flags["header_key"]
 	additionalHeaderVal := *flags["header_val"]
 
-	flipClient := telestream.NewFlipClient(apiKey, additionalHeaderKey, additionalHeaderVal, telestream.ServiceToStdOut)
-	ttsClient := telestream.NewTtsClient(apiKey, additionalHeaderKey, additionalHeaderVal, telestream.ServiceToStdOut)
+	output, err := telestream.GetServiceOutput(*flags["output"])
+	if err != nil {
+
+		fmt.Fprintln(os.Stderr, err.Error())
+		return telestream.ExitUsage
+	}
+
+	if *flags["columns"] != "" || *flags["template"] != "" {
+
+		output, err = telestream.NewServiceToFormatted(output, os.Stdout, strings.Split(*flags["columns"], ","),
+			*flags["template"])
+		if err != nil {
+
+			fmt.Fprintln(os.Stderr, err.Error())
+			return telestream.ExitUsage
+		}
+	}
+
+	if *flags["query"] != "" {
+
+		output, err = telestream.NewServiceToQueried(output, *flags["query"])
+		if err != nil {
+
+			fmt.Fprintln(os.Stderr, err.Error())
+			return telestream.ExitUsage
+		}
+	}
+
+	flipClient := telestream.NewFlipClient(credentials.ApiKey, additionalHeaderKey, additionalHeaderVal, output)
+	ttsClient := telestream.NewTtsClient(credentials.ApiKey, additionalHeaderKey, additionalHeaderVal, output)
 
-	// configure command
-	configureCmd := cli.NewFlaggedCommand(configureCmdStr, "api_key", createConfig, map[string]bool{"api_key": true},
-		"create configuration file for tsc command line tool with credentials that are used to interact with telestream cloud API")
+	flipClient.SetHeaders(headers)
+	ttsClient.SetHeaders(headers)
+
+	flipClient.SetHttpClient(httpClient)
+	ttsClient.SetHttpClient(httpClient)
+
+	if err := flipClient.SetEndpoint(settings.FlipEndpoint); err != nil {
+
+		fmt.Fprintln(os.Stderr, err.Error())
+		return telestream.ExitUsage
+	}
+
+	if err := ttsClient.SetEndpoint(settings.TtsEndpoint); err != nil {
+
+		fmt.Fprintln(os.Stderr, err.Error())
+		return telestream.ExitUsage
+	}
+
+	configClient := telestream.NewConfigClient(configFilePath, profile, flagApiKey, output)
 
 	commands := createFlipCommands(flipClient)
 	commands = append(commands, createTtsCommands(ttsClient)[0])
-	commands = append(commands, configureCmd)
+	commands = append(commands, createConfigureCommands(configClient))
+
+	cmdHndl := cli.NewCommandHandler("tcs", commands, additionalFlags)
 
-	cmdHndl := cli.NewCommandHandler("tcs", commands, map[string]string{"header_key": "additive http header key",
-		"header_val": "additive http header value"})
+	if err := cmdHndl.ParseArgs(argvOutput); err != nil {
+
+		telestream.PrintError(output, err)
+		return telestream.ExitCode(err)
+	}
 
-	cmdHndl.ParseArgs(argvOutput)
+	return telestream.ExitOk
 }
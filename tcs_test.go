@@ -0,0 +1,262 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"tcs-cli/telestream"
+)
+
+// Run tcs with given arguments, home directory and environment are restored afterwards
+func runTcs(t *testing.T, home string, env map[string]string, args ...string) int {
+
+	savedArgs := os.Args
+	defer func() { os.Args = savedArgs }()
+
+	for key, val := range map[string]string{"HOME": home, telestream.ApiKeyEnv: "", telestream.ConfigFileEnv: "",
+		telestream.ProfileEnv: ""} {
+
+		if envVal, ok := env[key]; ok {
+			val = envVal
+		}
+
+		defer os.Setenv(key, os.Getenv(key))
+		os.Setenv(key, val)
+	}
+
+	os.Args = append([]string{"tcs"}, args...)
+
+	return createTcsCli()
+}
+
+func Test_createTcsCli_endpoints(t *testing.T) {
+
+	requests := []string{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		requests = append(requests, r.URL.Path+" "+r.Header.Get("X-Api-Key"))
+
+		switch r.URL.Path {
+
+		case "/flip/factories.json":
+			w.Write([]byte(`{"factories":[{"id":"factory"}],"page":1,"per_page":1,"total":1}`))
+
+		case "/tts/projects":
+			w.Write([]byte(`{"projects":[{"id":"project"}]}`))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	home, err := ioutil.TempDir("", "tcs-home")
+	assert.Nil(t, err)
+	defer os.RemoveAll(home)
+
+	credentials := "[default]\napi_key = defaultkey\nflip_endpoint = " + server.URL + "/flip\n" +
+		"tts_endpoint = " + server.URL + "/tts/\n[staging]\napi_key = stagingkey\nflip_endpoint = " +
+		server.URL + "/staging\n"
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(home, telestream.ConfigFileName), []byte(credentials), 0600))
+
+	var testVector = []struct {
+		name    string
+		env     map[string]string
+		args    []string
+		code    int
+		request string
+	}{
+		{"profile flip endpoint", nil, []string{"flip", "factories", "list", "-output", "json"},
+			telestream.ExitOk, "/flip/factories.json defaultkey"},
+		{"profile tts endpoint", nil, []string{"tts", "projects", "list", "-output", "json"},
+			telestream.ExitOk, "/tts/projects defaultkey"},
+		{"selected profile endpoint", map[string]string{telestream.ProfileEnv: "staging"},
+			[]string{"flip", "factories", "list"}, telestream.ExitNotFound, "/staging/factories.json stagingkey"},
+		{"endpoint flag", nil, []string{"-profile", "staging", "-endpoint", server.URL + "/flip", "flip",
+			"factories", "list"}, telestream.ExitOk, "/flip/factories.json stagingkey"},
+		{"api key from environment", map[string]string{telestream.ApiKeyEnv: "envkey"},
+			[]string{"flip", "factories", "list"}, telestream.ExitOk, "/flip/factories.json envkey"},
+		{"invalid endpoint", nil, []string{"-endpoint", "localhost", "flip", "factories", "list"},
+			telestream.ExitUsage, ""},
+		{"no credentials", map[string]string{telestream.ProfileEnv: "prod"}, []string{"flip", "factories", "list"},
+			telestream.ExitAuth, ""},
+	}
+
+	for _, testEl := range testVector {
+
+		t.Run(testEl.name, func(t *testing.T) {
+
+			requests = []string{}
+
+			assert.Equal(t, testEl.code, runTcs(t, home, testEl.env, testEl.args...))
+
+			if testEl.request != "" {
+				assert.Equal(t, []string{testEl.request}, requests)
+			} else {
+				assert.Empty(t, requests)
+			}
+		})
+	}
+}
+
+func Test_createTcsCli_configure(t *testing.T) {
+
+	home, err := ioutil.TempDir("", "tcs-home")
+	assert.Nil(t, err)
+	defer os.RemoveAll(home)
+
+	assert.Equal(t, telestream.ExitOk, runTcs(t, home, nil, "configure", "-api_key", "defaultkey"))
+	assert.Equal(t, telestream.ExitOk, runTcs(t, home, nil, "configure", "-profile", "staging", "-api_key",
+		"stagingkey", "-flip_endpoint", "http://localhost:8080/flip"))
+	assert.Equal(t, telestream.ExitUsage, runTcs(t, home, nil, "configure", "-api_key", "key", "-tts_endpoint",
+		"localhost"))
+
+	// global -api_key is taken before configure word, the one after it is stored
+	assert.Equal(t, telestream.ExitOk, runTcs(t, home, nil, "-api_key", "flagkey", "configure", "show"))
+	assert.Equal(t, telestream.ExitOk, runTcs(t, home, nil, "-api_key", "flagkey", "configure", "-profile",
+		"other", "-api_key", "otherkey"))
+
+	config, err := telestream.LoadConfigFile(filepath.Join(home, telestream.ConfigFileName))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"default", "other", "staging"}, config.Profiles())
+	assert.Equal(t, "defaultkey", config.ApiKey("default"))
+	assert.Equal(t, "otherkey", config.ApiKey("other"))
+
+	settings, err := config.Settings("staging")
+	assert.Nil(t, err)
+	assert.Equal(t, telestream.ProfileSettings{FlipEndpoint: "http://localhost:8080/flip"}, settings)
+}
+
+func Test_createTcsCli_headers(t *testing.T) {
+
+	var headers http.Header
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		headers = r.Header
+		w.Write([]byte(`{"factories":[]}`))
+	}))
+	defer server.Close()
+
+	home, err := ioutil.TempDir("", "tcs-home")
+	assert.Nil(t, err)
+	defer os.RemoveAll(home)
+
+	credentials := "[default]\napi_key = defaultkey\nflip_endpoint = " + server.URL + "\n" +
+		"[default.headers]\nX-Tenant = tenant-a\nX-Region = eu\n"
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(home, telestream.ConfigFileName), []byte(credentials), 0600))
+
+	assert.Equal(t, telestream.ExitOk, runTcs(t, home, nil, "flip", "factories", "list"))
+	assert.Equal(t, "tenant-a", headers.Get("X-Tenant"))
+	assert.Equal(t, "eu", headers.Get("X-Region"))
+
+	assert.Equal(t, telestream.ExitOk, runTcs(t, home, nil, "-H", "X-Tenant: tenant-b", "flip", "factories",
+		"list", "-H", "X-Trace-Id: trace-1", "-header_key", "X-Legacy", "-header_val", "legacy"))
+	assert.Equal(t, "tenant-b", headers.Get("X-Tenant"))
+	assert.Equal(t, "eu", headers.Get("X-Region"))
+	assert.Equal(t, "trace-1", headers.Get("X-Trace-Id"))
+	assert.Equal(t, "legacy", headers.Get("X-Legacy"))
+
+	// header keys differing in case only are the same header
+	assert.Equal(t, telestream.ExitOk, runTcs(t, home, nil, "-H", "x-tenant: tenant-c", "flip", "factories",
+		"list"))
+	assert.Equal(t, []string{"tenant-c"}, headers["X-Tenant"])
+
+	assert.Equal(t, telestream.ExitUsage, runTcs(t, home, nil, "-H", "X-Tenant", "flip", "factories", "list"))
+}
+
+func Test_createTcsCli_logFile(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"factories":[{"id":"factory"}]}`))
+	}))
+	defer server.Close()
+
+	home, err := ioutil.TempDir("", "tcs-home")
+	assert.Nil(t, err)
+	defer os.RemoveAll(home)
+
+	logFile := filepath.Join(home, "tcs.log")
+	env := map[string]string{telestream.ApiKeyEnv: "secretkey1234"}
+
+	assert.Equal(t, telestream.ExitOk, runTcs(t, home, env, "-endpoint", server.URL, "-log_file", logFile,
+		"flip", "factories", "list"))
+	assert.Equal(t, telestream.ExitOk, runTcs(t, home, env, "-endpoint", server.URL, "-debug", "-log_file",
+		logFile, "flip", "factories", "list"))
+
+	log, err := ioutil.ReadFile(logFile)
+	assert.Nil(t, err)
+	assert.Contains(t, string(log), "GET "+server.URL+"/factories.json -> 200 OK")
+	assert.Contains(t, string(log), "> GET "+server.URL+"/factories.json\n")
+	assert.Contains(t, string(log), "> X-Api-Key: *********1234\n")
+	assert.Contains(t, string(log), "< {\"factories\":[{\"id\":\"factory\"}]}\n")
+	assert.NotContains(t, string(log), "secretkey1234")
+}
+
+func Test_createTcsCli_typedFlags(t *testing.T) {
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"videos":[]}`))
+	}))
+	defer server.Close()
+
+	home, err := ioutil.TempDir("", "tcs-home")
+	assert.Nil(t, err)
+	defer os.RemoveAll(home)
+
+	env := map[string]string{telestream.ApiKeyEnv: "secretkey1234"}
+
+	assert.Equal(t, telestream.ExitUsage, runTcs(t, home, env, "-endpoint", server.URL, "flip", "profiles",
+		"create", "-factory_id", "factory", "-preset_name", "h264", "-width", "wide"))
+	assert.Equal(t, telestream.ExitUsage, runTcs(t, home, env, "-endpoint", server.URL, "flip", "videos", "list",
+		"-factory_id", "factory", "-per_page", "ten"))
+	assert.Equal(t, telestream.ExitUsage, runTcs(t, home, env, "configure", "-api_key", "key", "-store",
+		"memory"))
+	assert.Equal(t, telestream.ExitUsage, runTcs(t, home, env, "-endpoint", server.URL, "flip", "videos",
+		"describe", "-factory_id", "-video_id", "video"))
+	assert.Equal(t, 0, requests)
+
+	assert.Equal(t, telestream.ExitOk, runTcs(t, home, env, "-endpoint", server.URL, "flip", "videos", "list",
+		"-factory_id", "factory", "-per_page", "10"))
+	assert.Equal(t, 1, requests)
+}
+
+func Test_createTcsCli_timeout(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		time.Sleep(200 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"video","status":"success"}`))
+	}))
+	defer server.Close()
+
+	home, err := ioutil.TempDir("", "tcs-home")
+	assert.Nil(t, err)
+	defer os.RemoveAll(home)
+
+	env := map[string]string{telestream.ApiKeyEnv: "secretkey1234"}
+
+	// global -timeout is taken before command, -timeout of wait command does not replace it
+	assert.Equal(t, telestream.ExitTimeout, runTcs(t, home, env, "-endpoint", server.URL, "-timeout", "50ms",
+		"flip", "videos", "wait", "-factory_id", "factory", "-video_id", "video", "-timeout", "1h"))
+	assert.Equal(t, telestream.ExitOk, runTcs(t, home, env, "-endpoint", server.URL, "flip", "videos", "wait",
+		"-factory_id", "factory", "-video_id", "video", "-timeout", "1h"))
+	assert.Equal(t, telestream.ExitUsage, runTcs(t, home, env, "-endpoint", server.URL, "-timeout", "soon",
+		"flip", "videos", "wait", "-factory_id", "factory", "-video_id", "video"))
+}
@@ -0,0 +1,166 @@
+package telestream
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"tcs-cli/cli"
+)
+
+// input of body file passed as -f -, replaced in tests
+var bodyFileInput io.Reader = os.Stdin
+
+// file flag of request body
+const bodyFileFlag = "f"
+
+// Add -f flag of yaml or json file with request body
+func addFileOpt(flags map[string]bool) {
+
+	flags[bodyFileFlag] = false
+}
+
+// Get flag types of given flag types with fields of request bodies marked as fields which can be set in -f file,
+// so required body fields are shown as required unless set in file and they are checked after file is read
+func addFileFlagTypes(types cli.FlagTypes, bodies ...interface{}) cli.FlagTypes {
+
+	merged := mergeFlagTypes(types)
+
+	for _, j := range bodies {
+		for _, name := range structToProperties(j) {
+
+			spec := merged[name]
+			spec.InFile = bodyFileFlag
+			merged[name] = spec
+		}
+	}
+
+	return merged
+}
+
+// Read request body from -f file (yaml or json, - reads stdin) and set body fields from flags, explicit
+// flags override file values. Body fields of required flags must be set in file or by flags. -f is deleted
+// from args map.
+func readRequestBody(j interface{}, argsMap *cli.FlagMap) error {
+
+	required := []string{}
+	for key, flagVal := range *argsMap {
+		if flagVal.IsRequired {
+			required = append(required, key)
+		}
+	}
+	sort.Strings(required)
+
+	if flagVal, ok := (*argsMap)[bodyFileFlag]; ok {
+
+		delete(*argsMap, bodyFileFlag)
+
+		if path := *flagVal.Value; path != "" {
+
+			if err := readBodyFile(path, j); err != nil {
+
+				return err
+			}
+		}
+	}
+
+	if err := propertiesToStruct(j, *argsMap); err != nil {
+
+		return err
+	}
+
+	e := reflect.ValueOf(j).Elem()
+
+	for _, name := range required {
+		for i := 0; i < e.NumField(); i++ {
+
+			if jsonFieldName(e.Type().Field(i)) != name {
+				continue
+			}
+
+			field := e.Field(i)
+			if reflect.DeepEqual(field.Interface(), reflect.Zero(field.Type()).Interface()) {
+
+				return cli.NewUsageError("Required flag not set: -" + name + " (pass it or set " + name +
+					" in -f file)")
+			}
+		}
+	}
+
+	return nil
+}
+
+// Decode yaml or json file into structure, fields are matched by json names and unknown fields are
+// rejected, so typos in file are not silently ignored
+func readBodyFile(path string, j interface{}) error {
+
+	var content []byte
+	var err error
+
+	if path == "-" {
+		content, err = ioutil.ReadAll(bodyFileInput)
+	} else {
+		content, err = ioutil.ReadFile(path)
+	}
+
+	if err != nil {
+
+		return cli.NewUsageError("Cannot read body file: " + err.Error())
+	}
+
+	// json is valid yaml, so both are decoded as yaml and converted to json to use json names of fields
+	var document interface{}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+
+		return cli.NewUsageError("Invalid body file " + path + ": " + err.Error())
+	}
+
+	jsonContent, err := json.Marshal(yamlToJson(document))
+	if err != nil {
+
+		return cli.NewUsageError("Invalid body file " + path + ": " + err.Error())
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(jsonContent))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(j); err != nil {
+
+		return cli.NewUsageError("Invalid body file " + path + ": " + strings.TrimPrefix(err.Error(), "json: "))
+	}
+
+	return nil
+}
+
+// Convert decoded yaml value to value which can be encoded as json (yaml maps have keys of any type)
+func yamlToJson(value interface{}) interface{} {
+
+	switch v := value.(type) {
+
+	case map[interface{}]interface{}:
+		object := map[string]interface{}{}
+		for key, val := range v {
+			object[fmt.Sprint(key)] = yamlToJson(val)
+		}
+
+		return object
+
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, val := range v {
+			list[i] = yamlToJson(val)
+		}
+
+		return list
+	}
+
+	return value
+}
@@ -0,0 +1,114 @@
+package telestream
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Telestream/telestream-cloud-go-sdk/flip"
+	"github.com/stretchr/testify/assert"
+
+	"tcs-cli/cli"
+)
+
+func Test_readRequestBody(t *testing.T) {
+
+	defer func(input io.Reader) { bodyFileInput = input }(bodyFileInput)
+
+	dir, err := ioutil.TempDir("", "tcs-body")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	yamlFile := filepath.Join(dir, "profile.yaml")
+	assert.Nil(t, ioutil.WriteFile(yamlFile, []byte("preset_name: h264\nname: web\nwidth: 1280\nfps: 29.97\n"+
+		"encryption: true\n"), 0600))
+
+	jsonFile := filepath.Join(dir, "video.json")
+	assert.Nil(t, ioutil.WriteFile(jsonFile, []byte(`{"source_url": "http://example.com/a.mp4", `+
+		`"subtitle_files": ["en.srt"], "extra_variables": {"a": "1"}}`), 0600))
+
+	invalidFile := filepath.Join(dir, "invalid.yaml")
+	assert.Nil(t, ioutil.WriteFile(invalidFile, []byte("name: [web\n"), 0600))
+
+	unknownFile := filepath.Join(dir, "unknown.yaml")
+	assert.Nil(t, ioutil.WriteFile(unknownFile, []byte("preset_name: h264\nwidht: 1280\n"), 0600))
+
+	var testVector = []struct {
+		name     string
+		body     interface{}
+		flags    map[string]string
+		stdin    string
+		required []string
+		output   interface{}
+		message  string
+	}{
+		{"yaml file", &flip.ProfileBody{}, map[string]string{"f": yamlFile}, "", []string{"preset_name"},
+			&flip.ProfileBody{PresetName: "h264", Name: "web", Width: 1280, Fps: 29.97, Encryption: true}, ""},
+		{"flags override file", &flip.ProfileBody{}, map[string]string{"f": yamlFile, "width": "1920",
+			"encryption": "false"}, "", []string{"preset_name"},
+			&flip.ProfileBody{PresetName: "h264", Name: "web", Width: 1920, Fps: 29.97}, ""},
+		{"json file", &flip.CreateVideoBody{}, map[string]string{"f": jsonFile}, "", []string{"source_url"},
+			&flip.CreateVideoBody{SourceUrl: "http://example.com/a.mp4", SubtitleFiles: []string{"en.srt"},
+				ExtraVariables: map[string]string{"a": "1"}}, ""},
+		{"map flags added to file map", &flip.CreateVideoBody{}, map[string]string{"f": jsonFile,
+			"extra_variables": "b=2"}, "", nil, &flip.CreateVideoBody{SourceUrl: "http://example.com/a.mp4",
+			SubtitleFiles: []string{"en.srt"}, ExtraVariables: map[string]string{"a": "1", "b": "2"}}, ""},
+		{"stdin", &flip.ProfileBody{}, map[string]string{"f": "-"}, "preset_name: webm\n", nil,
+			&flip.ProfileBody{PresetName: "webm"}, ""},
+		{"no file", &flip.ProfileBody{}, map[string]string{"preset_name": "h264", "f": ""}, "",
+			[]string{"preset_name"}, &flip.ProfileBody{PresetName: "h264"}, ""},
+		{"required field not set", &flip.ProfileBody{}, map[string]string{"name": "web"}, "",
+			[]string{"preset_name"}, nil,
+			"Required flag not set: -preset_name (pass it or set preset_name in -f file)"},
+		{"missing file", &flip.ProfileBody{}, map[string]string{"f": filepath.Join(dir, "missing.yaml")}, "", nil,
+			nil, "Cannot read body file: open " + filepath.Join(dir, "missing.yaml") + ": no such file or directory"},
+		{"invalid yaml", &flip.ProfileBody{}, map[string]string{"f": invalidFile}, "", nil, nil,
+			"Invalid body file " + invalidFile + ": yaml: line 1: did not find expected ',' or ']'"},
+		{"unknown field", &flip.ProfileBody{}, map[string]string{"f": unknownFile}, "", nil, nil,
+			"Invalid body file " + unknownFile + ": unknown field \"widht\""},
+		{"invalid field type", &flip.ProfileBody{}, map[string]string{"f": "-"}, "width: wide\n", nil, nil,
+			"Invalid body file -: cannot unmarshal string into Go struct field ProfileBody.width of type int32"},
+	}
+
+	for _, testEl := range testVector {
+		t.Run(testEl.name, func(t *testing.T) {
+
+			bodyFileInput = strings.NewReader(testEl.stdin)
+
+			argsMap := cli.FlagMap{}
+			for _, key := range testEl.required {
+				argsMap[key] = cli.FlagProperties{Value: new(string), IsRequired: true}
+			}
+
+			for key, value := range testEl.flags {
+				value := value
+				argsMap[key] = cli.FlagProperties{Value: &value, IsRequired: argsMap[key].IsRequired}
+			}
+
+			err := readRequestBody(testEl.body, &argsMap)
+
+			if testEl.message != "" {
+
+				assert.IsType(t, &cli.UsageError{}, err)
+				assert.EqualError(t, err, testEl.message)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, testEl.output, testEl.body)
+			assert.NotContains(t, argsMap, "f")
+		})
+	}
+}
+
+func Test_addFileFlagTypes(t *testing.T) {
+
+	types := addFileFlagTypes(cli.FlagTypes{"width": {Type: cli.IntFlag}, "factory_id": {}}, &flip.ProfileBody{})
+
+	assert.Equal(t, cli.FlagSpec{Type: cli.IntFlag, InFile: "f"}, types["width"])
+	assert.Equal(t, cli.FlagSpec{InFile: "f"}, types["preset_name"])
+	assert.Equal(t, cli.FlagSpec{}, types["factory_id"])
+}
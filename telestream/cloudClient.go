@@ -1,9 +1,17 @@
 package telestream
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"tcs-cli/cli"
 )
@@ -12,88 +20,574 @@ type ServiceOutput interface {
 	printError(fName string, err error)
 	printStructContent(j interface{})
 	printTable(colNames []interface{}, rows [][]interface{})
+	printCollection(j interface{}, colNames []interface{}, rows [][]interface{})
 	printInfo(info string)
 }
 
+// Get service output by its format name
+func GetServiceOutput(format string) (ServiceOutput, error) {
+
+	switch format {
+
+	case "", "table":
+		return ServiceToStdOut, nil
+
+	case "json":
+		return ServiceToJson, nil
+
+	case "yaml":
+		return ServiceToYaml, nil
+
+	case "csv":
+		return ServiceToCsv, nil
+	}
+
+	return nil, errors.New("Unknown output format: " + format)
+}
+
+// Check that endpoint is absolute http(s) url, trailing slash is removed so endpoint can be used as API base path
+func parseEndpoint(endpoint string) (string, error) {
+
+	endpointUrl, err := url.Parse(endpoint)
+	if err != nil || (endpointUrl.Scheme != "http" && endpointUrl.Scheme != "https") || endpointUrl.Host == "" {
+
+		return "", cli.NewUsageError("Invalid endpoint: " + endpoint)
+	}
+
+	return strings.TrimRight(endpoint, "/"), nil
+}
+
+// Parse http headers given as "Key: Value", keys are canonicalized (x-tenant is X-Tenant), later header
+// replaces earlier one with the same key
+func ParseHeaders(values []string) (map[string]string, error) {
+
+	headers := map[string]string{}
+
+	for _, value := range values {
+
+		colonIdx := strings.Index(value, ":")
+		if colonIdx <= 0 || strings.TrimSpace(value[:colonIdx]) == "" {
+
+			return nil, cli.NewUsageError("Invalid header: " + value + " (expected \"Key: Value\")")
+		}
+
+		headers[http.CanonicalHeaderKey(strings.TrimSpace(value[:colonIdx]))] = strings.TrimSpace(value[colonIdx+1:])
+	}
+
+	return headers, nil
+}
+
+// Get structure field name used in json (field name if json tag is not set)
+func jsonFieldName(varField reflect.StructField) string {
+
+	if jsonTag := varField.Tag.Get("json"); jsonTag != "" && jsonTag != "-" {
+
+		if commaIdx := strings.Index(jsonTag, ","); commaIdx > 0 {
+
+			return jsonTag[:commaIdx]
+		}
+
+		return jsonTag
+	}
+
+	return varField.Name
+}
+
+// Get field names and values of structure (or entries of map sorted by key), false is returned for other values
+func structContent(j interface{}) ([]string, []interface{}, bool) {
+
+	names := []string{}
+	values := []interface{}{}
+
+	e := reflect.ValueOf(j)
+	for e.Kind() == reflect.Ptr || e.Kind() == reflect.Interface {
+
+		if e.IsNil() {
+			return names, values, false
+		}
+		e = e.Elem()
+	}
+
+	switch e.Kind() {
+
+	case reflect.Struct:
+		for i := 0; i < e.NumField(); i++ {
+
+			names = append(names, jsonFieldName(e.Type().Field(i)))
+			values = append(values, e.Field(i).Interface())
+		}
+
+	case reflect.Map:
+		keys := e.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+
+		for _, key := range keys {
+
+			names = append(names, fmt.Sprint(key))
+			values = append(values, e.MapIndex(key).Interface())
+		}
+
+	default:
+		return names, values, false
+	}
+
+	return names, values, true
+}
+
+// Get elements of slice (or single element list for other values)
+func listContent(j interface{}) []interface{} {
+
+	e := reflect.ValueOf(j)
+	for e.Kind() == reflect.Ptr || e.Kind() == reflect.Interface {
+
+		if e.IsNil() {
+			return []interface{}{nil}
+		}
+		e = e.Elem()
+	}
+
+	if !e.IsValid() {
+
+		return []interface{}{nil}
+	}
+
+	if e.Kind() != reflect.Slice && e.Kind() != reflect.Array {
+
+		return []interface{}{e.Interface()}
+	}
+
+	elements := []interface{}{}
+	for i := 0; i < e.Len(); i++ {
+		elements = append(elements, e.Index(i).Interface())
+	}
+
+	return elements
+}
+
+// type of time fields, they are set from RFC 3339 time or date flags
+var timeType = reflect.TypeOf(time.Time{})
+
+// Get flag spec of structure field type, false is returned when field cannot be set from flag
+func fieldFlagSpec(fieldType reflect.Type) (cli.FlagSpec, bool) {
+
+	if fieldType == timeType {
+
+		return cli.FlagSpec{Type: cli.StringFlag}, true
+	}
+
+	switch fieldType.Kind() {
+
+	case reflect.String:
+		return cli.FlagSpec{Type: cli.StringFlag}, true
+
+	case reflect.Bool:
+		return cli.FlagSpec{Type: cli.BoolFlag}, true
+
+	case reflect.Int32, reflect.Int64:
+		return cli.FlagSpec{Type: cli.IntFlag}, true
+
+	case reflect.Float32, reflect.Float64:
+		return cli.FlagSpec{Type: cli.FloatFlag}, true
+
+	case reflect.Slice:
+		// comma separated list, e.g. -subtitle_files a.srt,b.srt
+		if fieldType.Elem().Kind() == reflect.String {
+
+			return cli.FlagSpec{Type: cli.ListFlag}, true
+		}
+
+	case reflect.Map:
+		// repeated key=value flag, e.g. -extra_variables a=1 -extra_variables b=2
+		if fieldType.Key().Kind() == reflect.String && fieldType.Elem().Kind() == reflect.String {
+
+			return cli.FlagSpec{Type: cli.StringFlag, Repeated: true}, true
+		}
+	}
+
+	return cli.FlagSpec{}, false
+}
+
+// Get type of nested structure of field (struct or pointer to struct), false for other types and time fields
+func nestedStructType(fieldType reflect.Type) (reflect.Type, bool) {
+
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+
+	return fieldType, fieldType.Kind() == reflect.Struct && fieldType != timeType
+}
+
+// Call visit for all structure fields which can be set from flags, fields of nested structures have dotted
+// names, e.g. watermark.url
+func visitStructFields(structType reflect.Type, prefix string, visit func(name string, spec cli.FlagSpec)) {
+
+	for i := 0; i < structType.NumField(); i++ {
+
+		field := structType.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		varName := prefix + jsonFieldName(field)
+
+		if nestedType, ok := nestedStructType(field.Type); ok {
+
+			visitStructFields(nestedType, varName+".", visit)
+			continue
+		}
+
+		if spec, ok := fieldFlagSpec(field.Type); ok {
+			visit(varName, spec)
+		}
+	}
+}
+
 // Convert all structure field names to string slice
 func structToProperties(j interface{}) []string {
 
 	propertiesList := []string{}
 
-	e := reflect.ValueOf(j).Elem()
+	visitStructFields(reflect.TypeOf(j).Elem(), "", func(name string, spec cli.FlagSpec) {
+		propertiesList = append(propertiesList, name)
+	})
 
-	for i := 0; i < e.NumField(); i++ {
-
-		varField := e.Type().Field(i)
-		varName := varField.Name
-		varType := e.Field(i).Type().String()
+	return propertiesList
+}
 
-		if jsonTag := varField.Tag.Get("json"); jsonTag != "" && jsonTag != "-" {
+// Get flag types of structure fields converted by structToProperties (string fields are untyped)
+func structToFlagTypes(j interface{}) cli.FlagTypes {
 
-			if commaIdx := strings.Index(jsonTag, ","); commaIdx > 0 {
+	types := cli.FlagTypes{}
 
-				varName = jsonTag[:commaIdx]
-			} else {
+	visitStructFields(reflect.TypeOf(j).Elem(), "", func(name string, spec cli.FlagSpec) {
 
-				varName = jsonTag
-			}
+		if spec.Type != cli.StringFlag || spec.Repeated {
+			types[name] = spec
 		}
+	})
+
+	return types
+}
 
-		if varType == "string" || varType == "int32" || varType == "bool" {
+// Merge flag types, later types replace earlier types of the same flag
+func mergeFlagTypes(types ...cli.FlagTypes) cli.FlagTypes {
 
-			propertiesList = append(propertiesList, varName)
+	merged := cli.FlagTypes{}
+
+	for _, flagTypes := range types {
+		for key, spec := range flagTypes {
+			merged[key] = spec
 		}
 	}
 
-	return propertiesList
+	return merged
+}
+
+// set structure field basing on map -> k -> field name, *v.Value -> field value, flags which are not set are
+// skipped and invalid value is returned as usage error
+func propertiesToStruct(j interface{}, argsMap cli.FlagMap) error {
+
+	_, err := setStructFields(reflect.ValueOf(j).Elem(), "", argsMap)
+
+	return err
 }
 
-// set structure field basing on map -> k -> field name, *v.Value -> field value
-func propertiesToStruct(j interface{}, argsMap cli.FlagMap) {
+// Set structure fields from flags, nested structure pointer is allocated only when any of its fields is set
+func setStructFields(e reflect.Value, prefix string, argsMap cli.FlagMap) (bool, error) {
 
-	e := reflect.ValueOf(j).Elem()
+	isSet := false
 
 	for i := 0; i < e.NumField(); i++ {
 
-		varField := e.Type().Field(i)
-		varName := varField.Name
-		varType := e.Field(i).Type().String()
+		field := e.Type().Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
 
-		if jsonTag := varField.Tag.Get("json"); jsonTag != "" && jsonTag != "-" {
+		varName := prefix + jsonFieldName(field)
+		fieldValue := e.Field(i)
 
-			if commaIdx := strings.Index(jsonTag, ","); commaIdx > 0 {
+		if nestedType, ok := nestedStructType(field.Type); ok {
 
-				varName = jsonTag[:commaIdx]
-			} else {
+			nested := fieldValue
+			if field.Type.Kind() == reflect.Ptr {
+				nested = reflect.New(nestedType).Elem()
+			}
 
-				varName = jsonTag
+			nestedSet, err := setStructFields(nested, varName+".", argsMap)
+			if err != nil {
+
+				return false, err
+			}
+
+			if nestedSet && field.Type.Kind() == reflect.Ptr {
+				fieldValue.Set(nested.Addr())
 			}
+
+			isSet = isSet || nestedSet
+			continue
+		}
+
+		val, ok := argsMap[varName]
+		if !ok || *val.Value == "" {
+			continue
+		}
+
+		if _, ok := fieldFlagSpec(field.Type); !ok {
+			continue
 		}
 
-		if val, ok := argsMap[varName]; ok {
+		if invalid, err := setFieldValue(fieldValue, flagValues(val)); err != nil {
+
+			return false, cli.NewUsageError("Invalid value of -" + varName + ": " + invalid)
+		}
+
+		isSet = true
+	}
+
+	return isSet, nil
+}
+
+// Set field from flag values, list and map fields use all values, other fields the last one. Invalid value
+// is returned with error.
+func setFieldValue(field reflect.Value, values []string) (string, error) {
+
+	value := values[len(values)-1]
 
-			switch varType {
+	if field.Type() == timeType {
 
-			case "string":
-				e.Field(i).SetString(*val.Value)
+		t, err := parseTime(value)
+		field.Set(reflect.ValueOf(t))
 
-			case "bool":
-				b, _ := strconv.ParseBool(*val.Value)
-				e.Field(i).SetBool(b)
+		return value, err
+	}
+
+	var err error
+
+	switch field.Kind() {
+
+	case reflect.String:
+		field.SetString(value)
+
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(value)
+		field.SetBool(b)
 
-			case "int32":
-				in, _ := strconv.ParseInt(*val.Value, 10, 32)
-				e.Field(i).SetInt(in)
+	case reflect.Int32, reflect.Int64:
+		var in int64
+		in, err = strconv.ParseInt(value, 10, field.Type().Bits())
+		field.SetInt(in)
 
+	case reflect.Float32, reflect.Float64:
+		var fl float64
+		fl, err = strconv.ParseFloat(value, field.Type().Bits())
+		field.SetFloat(fl)
+
+	case reflect.Slice:
+		list := reflect.MakeSlice(field.Type(), 0, len(values))
+		for _, value := range values {
+			for _, element := range strings.Split(value, ",") {
+				if element = strings.TrimSpace(element); element != "" {
+
+					list = reflect.Append(list, reflect.ValueOf(element).Convert(field.Type().Elem()))
+				}
 			}
 		}
+		field.Set(list)
+
+	case reflect.Map:
+		// entries are added to map read from body file
+		entries := field
+		if entries.IsNil() {
+			entries = reflect.MakeMap(field.Type())
+		}
+		for _, value := range values {
+
+			keyValue := strings.SplitN(value, "=", 2)
+			if len(keyValue) != 2 || strings.TrimSpace(keyValue[0]) == "" {
+
+				return value + " (KEY=VALUE expected)", errors.New("invalid map entry")
+			}
+
+			entries.SetMapIndex(reflect.ValueOf(strings.TrimSpace(keyValue[0])).Convert(field.Type().Key()),
+				reflect.ValueOf(keyValue[1]).Convert(field.Type().Elem()))
+		}
+		field.Set(entries)
+	}
+
+	return value, err
+}
+
+// Get all values of flag, values of repeated and list flags are kept in Values
+func flagValues(val cli.FlagProperties) []string {
+
+	if val.Values != nil && len(*val.Values) > 0 {
+
+		return *val.Values
 	}
+
+	return []string{*val.Value}
 }
 
+// Parse time flag given as RFC 3339 time (e.g. 2019-06-03T10:00:00Z) or date (2019-06-03)
+func parseTime(value string) (time.Time, error) {
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+
+		return t, nil
+	}
+
+	return time.Parse("2006-01-02", value)
+}
+
+// types of flags shared by flip and tts commands
+var commonFlagTypes = cli.FlagTypes{"page": {Type: cli.IntFlag}, "per_page": {Type: cli.IntFlag},
+	"all": {Type: cli.BoolFlag}, "limit": {Type: cli.IntFlag}, "timeout": {Type: cli.DurationFlag},
+	"dry_run": {Type: cli.BoolFlag}, "yes": {Type: cli.BoolFlag}, "force": {Type: cli.BoolFlag}}
+
 func addPageOpt(flags map[string]bool) {
 
 	flags["page"] = false
 	flags["per_page"] = false
+	flags["all"] = false
+	flags["limit"] = false
+}
+
+// Get information if all pages should be fetched and maximal number of fetched items (0 - no limit)
+func getAllPagesOpt(argsMap *cli.FlagMap) (bool, int, error) {
+
+	all := false
+	limit := 0
+
+	if flagVal, ok := (*argsMap)["all"]; ok {
+
+		delete(*argsMap, "all")
+		if *flagVal.Value != "" {
+			b, err := strconv.ParseBool(*flagVal.Value)
+			if err != nil {
+				return all, limit, cli.NewUsageError("Invalid all: " + *flagVal.Value)
+			}
+			all = b
+		}
+	}
+
+	if flagVal, ok := (*argsMap)["limit"]; ok {
+
+		delete(*argsMap, "limit")
+		if *flagVal.Value != "" {
+			i, err := strconv.ParseInt(*flagVal.Value, 10, 32)
+			if err != nil || i < 0 {
+				return all, limit, cli.NewUsageError("Invalid limit: " + *flagVal.Value)
+			}
+			limit = int(i)
+		}
+	}
+
+	return all, limit, nil
+}
+
+// Call fetch for consecutive pages (starting from page given in opts) until all items are fetched or limit is
+// reached, fetch returns number of items on fetched page and total number of items (0 if unknown).
+// If all is not set and there is no limit only one page is fetched.
+func fetchPages(opts map[string]interface{}, all bool, limit int,
+	fetch func(opts map[string]interface{}) (int, int, error)) error {
+
+	if !all && limit <= 0 {
+
+		_, _, err := fetch(opts)
+		return err
+	}
+
+	page := int32(1)
+	if optPage, ok := opts["page"].(int32); ok {
+
+		page = optPage
+	}
+
+	perPage := 0
+	if optPerPage, ok := opts["perPage"].(int32); ok {
+
+		perPage = int(optPerPage)
+	}
+
+	// items of pages before the first fetched one are counted in total number of items
+	skippedItems := -1
+	fetchedItems := 0
+
+	for {
+
+		opts["page"] = page
+
+		pageItems, totalItems, err := fetch(opts)
+		if err != nil {
+
+			return err
+		}
+
+		if skippedItems < 0 {
+
+			// without per_page the first page is taken as full page (shorter page is the last one)
+			if perPage <= 0 {
+				perPage = pageItems
+			}
+			skippedItems = int(page-1) * perPage
+		}
+
+		fetchedItems += pageItems
+
+		if pageItems == 0 || pageItems < perPage || (totalItems > 0 && skippedItems+fetchedItems >= totalItems) ||
+			(limit > 0 && fetchedItems >= limit) {
+
+			return nil
+		}
+
+		page++
+	}
+}
+
+// Fetch pages (see fetchPages) of paginated collection, items of next pages are appended to itemsField slice
+// of the first page and cut to limit (if set). fetch returns page collection and total number of items.
+func fetchCollection(opts map[string]interface{}, all bool, limit int, collection interface{}, itemsField string,
+	fetch func(opts map[string]interface{}) (interface{}, int, error)) error {
+
+	collectionValue := reflect.ValueOf(collection).Elem()
+	fetched := false
+
+	err := fetchPages(opts, all, limit, func(opts map[string]interface{}) (int, int, error) {
+
+		page, total, err := fetch(opts)
+		if err != nil {
+
+			return 0, 0, err
+		}
+
+		pageValue := reflect.ValueOf(page)
+		pageItems := pageValue.FieldByName(itemsField)
+
+		if !fetched {
+
+			collectionValue.Set(pageValue)
+			fetched = true
+		} else {
+
+			items := collectionValue.FieldByName(itemsField)
+			items.Set(reflect.AppendSlice(items, pageItems))
+		}
+
+		return pageItems.Len(), total, nil
+	})
+
+	if err != nil {
+
+		return err
+	}
+
+	if items := collectionValue.FieldByName(itemsField); limit > 0 && items.Len() > limit {
+		items.Set(items.Slice(0, limit))
+	}
+
+	return nil
 }
 
 func getPageOpt(argsMap *cli.FlagMap) (map[string]interface{}, error) {
@@ -108,7 +602,7 @@ func getPageOpt(argsMap *cli.FlagMap) (map[string]interface{}, error) {
 			if *flagVal.Value != "" {
 				i, err := strconv.ParseInt(*flagVal.Value, 10, 32)
 				if err != nil {
-					return resMap, err
+					return resMap, cli.NewUsageError("Invalid " + key + ": " + *flagVal.Value)
 				}
 				resMap[val] = int32(i)
 			}
@@ -126,3 +620,97 @@ func getPageOpt(argsMap *cli.FlagMap) (map[string]interface{}, error) {
 
 	return resMap, nil
 }
+
+// Check if file is connected to terminal (character device)
+func isTerminal(file *os.File) bool {
+
+	fileInfo, err := file.Stat()
+
+	return err == nil && fileInfo.Mode()&os.ModeCharDevice != 0
+}
+
+// delays between polls of waited resource, delay grows after every poll up to max delay
+var waitMinDelay = 2 * time.Second
+var waitMaxDelay = 30 * time.Second
+
+// terminal statuses of videos, encodings and tts jobs, value tells if status means failure
+var terminalStatuses = map[string]bool{"success": false, "fail": true, "failed": true, "error": true,
+	"cancelled": true, "canceled": true}
+
+// Parse duration given as go duration (e.g. 90s, 30m, 1h) or number of seconds
+func parseDuration(value string) (time.Duration, error) {
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+
+		return time.Duration(seconds) * time.Second, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+
+		return 0, cli.NewUsageError("Invalid duration: " + value)
+	}
+
+	return duration, nil
+}
+
+// Get wait timeout from args map and delete it from map, no timeout (0) when not set
+func getWaitTimeout(argsMap *cli.FlagMap) (time.Duration, error) {
+
+	timeout := time.Duration(0)
+	var err error
+
+	if *(*argsMap)["timeout"].Value != "" {
+
+		timeout, err = parseDuration(*(*argsMap)["timeout"].Value)
+	}
+
+	delete(*argsMap, "timeout")
+
+	return timeout, err
+}
+
+// Poll status with growing delay until it is terminal or timeout (0 - no timeout) elapses. Returns last
+// status, true if status means failure and error of poll (or timeout).
+func waitForStatus(ctx context.Context, timeout time.Duration, poll func(ctx context.Context) (string, error)) (string,
+	bool, error) {
+
+	if timeout > 0 {
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	delay := waitMinDelay
+
+	for {
+
+		status, err := poll(ctx)
+		if err != nil {
+
+			if ctx.Err() == context.DeadlineExceeded {
+
+				return status, false, &TimeoutError{"Timeout elapsed while waiting (" + timeout.String() + ")"}
+			}
+
+			return status, false, err
+		}
+
+		if failed, ok := terminalStatuses[strings.ToLower(status)]; ok {
+
+			return status, failed, nil
+		}
+
+		if err := sleepContext(ctx, delay); err != nil {
+
+			return status, false, &TimeoutError{"Timeout elapsed while waiting (" + timeout.String() +
+				"), last status: " + status}
+		}
+
+		delay = delay * 3 / 2
+		if delay > waitMaxDelay {
+			delay = waitMaxDelay
+		}
+	}
+}
@@ -1,9 +1,12 @@
 package telestream
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -37,6 +40,24 @@ func Test_structToProperties(t *testing.T) {
 		Field2 int32
 	}
 
+	type NestedStruct struct {
+		Url    string  `json:"url"`
+		Height float32 `json:"height"`
+	}
+
+	type TestedStruct6 struct {
+		Field1 []string            `json:"field_1"`
+		Field2 map[string]string   `json:"field_2"`
+		Field3 int64               `json:"field_3"`
+		Field4 float64             `json:"field_4"`
+		Field5 time.Time           `json:"field_5"`
+		Field6 NestedStruct        `json:"field_6"`
+		Field7 *NestedStruct       `json:"field_7"`
+		Field8 []NestedStruct      `json:"field_8"`
+		Field9 map[string][]string `json:"field_9"`
+		field  string
+	}
+
 	var testVector = []struct {
 		structure  interface{}
 		properties []string
@@ -46,6 +67,8 @@ func Test_structToProperties(t *testing.T) {
 		{&TestedStruct3{}, []string{"field_1", "Field2"}},
 		{&TestedStruct4{}, []string{"field_1"}},
 		{&TestedStruct5{}, []string{"Field1", "Field2"}},
+		{&TestedStruct6{}, []string{"field_1", "field_2", "field_3", "field_4", "field_5", "field_6.url",
+			"field_6.height", "field_7.url", "field_7.height"}},
 	}
 
 	for _, testEl := range testVector {
@@ -59,6 +82,27 @@ func Test_structToProperties(t *testing.T) {
 	}
 }
 
+func Test_structToFlagTypes(t *testing.T) {
+
+	type NestedStruct struct {
+		Url    string  `json:"url"`
+		Height float32 `json:"height"`
+	}
+
+	type TestedStruct struct {
+		Field1 string            `json:"field_1"`
+		Field2 bool              `json:"field_2"`
+		Field3 int64             `json:"field_3"`
+		Field4 []string          `json:"field_4"`
+		Field5 map[string]string `json:"field_5"`
+		Field6 *NestedStruct     `json:"field_6"`
+	}
+
+	assert.Equal(t, cli.FlagTypes{"field_2": {Type: cli.BoolFlag}, "field_3": {Type: cli.IntFlag},
+		"field_4": {Type: cli.ListFlag}, "field_5": {Type: cli.StringFlag, Repeated: true},
+		"field_6.height": {Type: cli.FloatFlag}}, structToFlagTypes(&TestedStruct{}))
+}
+
 func Test_propertiesToStruct(t *testing.T) {
 
 	type TestedStruct1 struct {
@@ -82,6 +126,35 @@ func Test_propertiesToStruct(t *testing.T) {
 	fieldBool := "true"
 	fieldint32 := "32"
 
+	type NestedStruct struct {
+		Url    string  `json:"url"`
+		Height float32 `json:"height"`
+	}
+
+	type TestedStruct4 struct {
+		Field1 []string          `json:"field_1"`
+		Field2 map[string]string `json:"field_2"`
+		Field3 int64             `json:"field_3"`
+		Field4 float64           `json:"field_4"`
+		Field5 time.Time         `json:"field_5"`
+	}
+
+	type TestedStruct5 struct {
+		Field1 NestedStruct  `json:"field_1"`
+		Field2 *NestedStruct `json:"field_2"`
+		Field3 *NestedStruct `json:"field_3"`
+	}
+
+	fieldList := "a, b,,c"
+	fieldListValues := []string{"a,b", "c"}
+	fieldMap := "k2=v=2"
+	fieldMapValues := []string{"k1=v1", " k2=v=2"}
+	fieldInt64 := "3000000000"
+	fieldFloat := "29.97"
+	fieldTime := "2019-06-03T10:00:00Z"
+	fieldDate := "2019-06-03"
+	fieldUrl := "http://example.com/logo.png"
+
 	var testVector = []struct {
 		structure    interface{}
 		flagMap      cli.FlagMap
@@ -96,11 +169,22 @@ func Test_propertiesToStruct(t *testing.T) {
 		{&TestedStruct3{}, cli.FlagMap{"Field1": cli.FlagProperties{Value: &fieldBool, IsRequired: true},
 			"Field2": cli.FlagProperties{Value: &fieldint32, IsRequired: true}},
 			&TestedStruct3{true, 32}},
+		{&TestedStruct4{}, cli.FlagMap{"field_1": {Value: &fieldList}, "field_2": {Value: &fieldMap},
+			"field_3": {Value: &fieldInt64}, "field_4": {Value: &fieldFloat}, "field_5": {Value: &fieldTime}},
+			&TestedStruct4{[]string{"a", "b", "c"}, map[string]string{"k2": "v=2"}, 3000000000, 29.97,
+				time.Date(2019, 6, 3, 10, 0, 0, 0, time.UTC)}},
+		{&TestedStruct4{}, cli.FlagMap{"field_1": {Value: &fieldList, Values: &fieldListValues},
+			"field_2": {Value: &fieldMap, Values: &fieldMapValues}, "field_5": {Value: &fieldDate}},
+			&TestedStruct4{[]string{"a", "b", "c"}, map[string]string{"k1": "v1", "k2": "v=2"}, 0, 0,
+				time.Date(2019, 6, 3, 0, 0, 0, 0, time.UTC)}},
+		{&TestedStruct5{}, cli.FlagMap{"field_1.url": {Value: &fieldUrl}, "field_2.height": {Value: &fieldFloat}},
+			&TestedStruct5{NestedStruct{Url: fieldUrl}, &NestedStruct{Height: 29.97}, nil}},
 	}
 
 	for _, testEl := range testVector {
 
-		propertiesToStruct(testEl.structure, testEl.flagMap)
+		err := propertiesToStruct(testEl.structure, testEl.flagMap)
+		assert.Nil(t, err)
 
 		if !reflect.DeepEqual(testEl.structure, testEl.outputStruct) {
 
@@ -109,11 +193,68 @@ func Test_propertiesToStruct(t *testing.T) {
 	}
 }
 
+func Test_propertiesToStruct_errors(t *testing.T) {
+
+	type NestedStruct struct {
+		Height float32 `json:"height"`
+	}
+
+	type TestedStruct struct {
+		Field1 bool              `json:"field_1"`
+		Field2 int32             `json:"field_2"`
+		Field3 map[string]string `json:"field_3"`
+		Field4 time.Time         `json:"field_4"`
+		Field5 *NestedStruct     `json:"field_5"`
+	}
+
+	invalidBool := "maybe"
+	invalidInt := "1.5"
+	outOfRange := "3000000000"
+	empty := ""
+	invalidEntry := "novalue"
+	invalidEntries := []string{"k=v", "novalue"}
+	invalidTime := "03.06.2019"
+
+	var testVector = []struct {
+		name    string
+		flagMap cli.FlagMap
+		message string
+	}{
+		{"invalid bool", cli.FlagMap{"field_1": {Value: &invalidBool}}, "Invalid value of -field_1: maybe"},
+		{"invalid int32", cli.FlagMap{"field_2": {Value: &invalidInt}}, "Invalid value of -field_2: 1.5"},
+		{"int32 out of range", cli.FlagMap{"field_2": {Value: &outOfRange}},
+			"Invalid value of -field_2: 3000000000"},
+		{"map entry without value", cli.FlagMap{"field_3": {Value: &invalidEntry, Values: &invalidEntries}},
+			"Invalid value of -field_3: novalue (KEY=VALUE expected)"},
+		{"invalid time", cli.FlagMap{"field_4": {Value: &invalidTime}}, "Invalid value of -field_4: 03.06.2019"},
+		{"invalid nested float", cli.FlagMap{"field_5.height": {Value: &invalidBool}},
+			"Invalid value of -field_5.height: maybe"},
+		{"empty values are skipped", cli.FlagMap{"field_1": {Value: &empty}, "field_2": {Value: &empty}}, ""},
+	}
+
+	for _, testEl := range testVector {
+		t.Run(testEl.name, func(t *testing.T) {
+
+			err := propertiesToStruct(&TestedStruct{}, testEl.flagMap)
+
+			if testEl.message == "" {
+
+				assert.Nil(t, err)
+			} else {
+
+				assert.IsType(t, &cli.UsageError{}, err)
+				assert.EqualError(t, err, testEl.message)
+			}
+		})
+	}
+}
+
 func Test_PageOpt(t *testing.T) {
 
 	flags := map[string]bool{}
 	addPageOpt(flags)
-	assert.True(t, reflect.DeepEqual(flags, map[string]bool{"page": false, "per_page": false}))
+	assert.True(t, reflect.DeepEqual(flags, map[string]bool{"page": false, "per_page": false, "all": false,
+		"limit": false}))
 
 	emptyString := ""
 	one := "1"
@@ -133,3 +274,284 @@ func Test_PageOpt(t *testing.T) {
 	assert.True(t, reflect.DeepEqual(opts, map[string]interface{}{}))
 	assert.True(t, reflect.DeepEqual(argsMap, cli.FlagMap{"some_flag": cli.FlagProperties{Value: &emptyString, IsRequired: false}}))
 }
+
+func Test_AllPagesOpt(t *testing.T) {
+
+	emptyString := ""
+	trueString := "true"
+	ten := "10"
+	wrong := "wrong"
+	negative := "-1"
+
+	argsMap := cli.FlagMap{"all": {Value: &trueString, IsRequired: false}, "limit": {Value: &ten, IsRequired: false},
+		"some_flag": {Value: &emptyString, IsRequired: false}}
+
+	all, limit, err := getAllPagesOpt(&argsMap)
+	assert.Nil(t, err)
+	assert.True(t, all)
+	assert.Equal(t, 10, limit)
+	assert.True(t, reflect.DeepEqual(argsMap, cli.FlagMap{"some_flag": cli.FlagProperties{Value: &emptyString, IsRequired: false}}))
+
+	argsMap["all"] = cli.FlagProperties{Value: &emptyString, IsRequired: false}
+	all, limit, err = getAllPagesOpt(&argsMap)
+	assert.Nil(t, err)
+	assert.False(t, all)
+	assert.Equal(t, 0, limit)
+
+	argsMap["limit"] = cli.FlagProperties{Value: &wrong, IsRequired: false}
+	_, _, err = getAllPagesOpt(&argsMap)
+	assert.NotNil(t, err)
+
+	argsMap["limit"] = cli.FlagProperties{Value: &negative, IsRequired: false}
+	_, _, err = getAllPagesOpt(&argsMap)
+	assert.NotNil(t, err)
+}
+
+func Test_fetchPages(t *testing.T) {
+
+	var testVector = []struct {
+		name       string
+		opts       map[string]interface{}
+		all        bool
+		limit      int
+		pageItems  []int
+		totalItems int
+		pages      []int32
+	}{
+		{"single page", map[string]interface{}{}, false, 0, []int{2, 2, 2}, 6, []int32{0}},
+		{"all pages", map[string]interface{}{}, true, 0, []int{2, 2, 2}, 6, []int32{1, 2, 3}},
+		{"all pages from given page", map[string]interface{}{"page": int32(2)}, true, 0, []int{2, 2, 2}, 8,
+			[]int32{2, 3, 4}},
+		{"all pages from given page with per page", map[string]interface{}{"page": int32(3), "perPage": int32(3)}, true,
+			0, []int{3, 3, 3}, 15, []int32{3, 4, 5}},
+		{"limit from given page", map[string]interface{}{"page": int32(2)}, false, 4, []int{2, 2, 2}, 8,
+			[]int32{2, 3}},
+		{"all pages ending with short page", map[string]interface{}{}, true, 0, []int{2, 1}, 0, []int32{1, 2}},
+		{"all pages without total", map[string]interface{}{}, true, 0, []int{2, 2, 0}, 0, []int32{1, 2, 3}},
+		{"limit", map[string]interface{}{}, false, 3, []int{2, 2, 2}, 6, []int32{1, 2}},
+		{"all pages with limit", map[string]interface{}{}, true, 2, []int{2, 2, 2}, 6, []int32{1}},
+	}
+
+	for _, testEl := range testVector {
+
+		t.Run(testEl.name, func(t *testing.T) {
+
+			pages := []int32{}
+
+			err := fetchPages(testEl.opts, testEl.all, testEl.limit, func(opts map[string]interface{}) (int, int, error) {
+
+				page, _ := opts["page"].(int32)
+				pages = append(pages, page)
+
+				return testEl.pageItems[len(pages)-1], testEl.totalItems, nil
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, testEl.pages, pages)
+		})
+	}
+
+	calls := 0
+	err := fetchPages(map[string]interface{}{}, true, 0, func(opts map[string]interface{}) (int, int, error) {
+
+		calls++
+		return 1, 10, errors.New("some error")
+	})
+
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func Test_fetchCollection(t *testing.T) {
+
+	type pageCollection struct {
+		Items []string
+		Total int32
+	}
+
+	pages := []pageCollection{{[]string{"a", "b"}, 5}, {[]string{"c", "d"}, 5}, {[]string{"e"}, 5}}
+
+	var testVector = []struct {
+		name   string
+		all    bool
+		limit  int
+		failAt int
+		items  []string
+	}{
+		{"single page", false, 0, 0, []string{"a", "b"}},
+		{"all pages", true, 0, 0, []string{"a", "b", "c", "d", "e"}},
+		{"limit", false, 3, 0, []string{"a", "b", "c"}},
+		{"error", true, 0, 2, nil},
+	}
+
+	for _, testEl := range testVector {
+
+		t.Run(testEl.name, func(t *testing.T) {
+
+			collection := pageCollection{}
+			fetched := 0
+
+			err := fetchCollection(map[string]interface{}{}, testEl.all, testEl.limit, &collection, "Items",
+				func(opts map[string]interface{}) (interface{}, int, error) {
+
+					fetched++
+					if fetched == testEl.failAt {
+
+						return pageCollection{}, 0, errors.New("some error")
+					}
+
+					page := pages[fetched-1]
+					return page, int(page.Total), nil
+				})
+
+			if testEl.items == nil {
+
+				assert.NotNil(t, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, testEl.items, collection.Items)
+			assert.Equal(t, int32(5), collection.Total)
+		})
+	}
+}
+
+func Test_parseDuration(t *testing.T) {
+
+	var testVector = []struct {
+		value    string
+		duration time.Duration
+		valid    bool
+	}{
+		{"90", 90 * time.Second, true},
+		{"0", 0, true},
+		{"30m", 30 * time.Minute, true},
+		{"1h30m", 90 * time.Minute, true},
+		{"-5s", 0, false},
+		{"soon", 0, false},
+	}
+
+	for _, testEl := range testVector {
+
+		t.Run(testEl.value, func(t *testing.T) {
+
+			duration, err := parseDuration(testEl.value)
+			assert.Equal(t, testEl.valid, err == nil)
+			assert.Equal(t, testEl.duration, duration)
+		})
+	}
+}
+
+func Test_parseEndpoint(t *testing.T) {
+
+	var testVector = []struct {
+		value    string
+		basePath string
+		valid    bool
+	}{
+		{"https://api.staging.example.com/flip/3.1", "https://api.staging.example.com/flip/3.1", true},
+		{"http://localhost:8080/tts/", "http://localhost:8080/tts", true},
+		{"localhost:8080", "", false},
+		{"ftp://example.com", "", false},
+		{"https://", "", false},
+	}
+
+	for _, testEl := range testVector {
+
+		t.Run(testEl.value, func(t *testing.T) {
+
+			basePath, err := parseEndpoint(testEl.value)
+			assert.Equal(t, testEl.valid, err == nil)
+			assert.Equal(t, testEl.basePath, basePath)
+
+			if err != nil {
+				assert.Equal(t, ExitUsage, ExitCode(err))
+			}
+		})
+	}
+}
+
+func Test_ParseHeaders(t *testing.T) {
+
+	var testVector = []struct {
+		name    string
+		values  []string
+		headers map[string]string
+		valid   bool
+	}{
+		{"no headers", nil, map[string]string{}, true},
+		{"headers", []string{"X-Tenant: a", "X-Trace-Id:b:c", "X-Empty:"},
+			map[string]string{"X-Tenant": "a", "X-Trace-Id": "b:c", "X-Empty": ""}, true},
+		{"repeated header", []string{"X-Tenant: a", "X-Tenant: b"}, map[string]string{"X-Tenant": "b"}, true},
+		{"canonical keys", []string{"X-Tenant: a", "x-tenant: b", "x-trace-id: c"},
+			map[string]string{"X-Tenant": "b", "X-Trace-Id": "c"}, true},
+		{"no colon", []string{"X-Tenant"}, nil, false},
+		{"no key", []string{" : a"}, nil, false},
+	}
+
+	for _, testEl := range testVector {
+
+		t.Run(testEl.name, func(t *testing.T) {
+
+			headers, err := ParseHeaders(testEl.values)
+			assert.Equal(t, testEl.valid, err == nil)
+			assert.Equal(t, testEl.headers, headers)
+		})
+	}
+}
+
+func Test_waitForStatus(t *testing.T) {
+
+	defer func(minDelay, maxDelay time.Duration) {
+		waitMinDelay, waitMaxDelay = minDelay, maxDelay
+	}(waitMinDelay, waitMaxDelay)
+
+	waitMinDelay = time.Millisecond
+	waitMaxDelay = 2 * time.Millisecond
+
+	var testVector = []struct {
+		name     string
+		statuses []string
+		status   string
+		failed   bool
+	}{
+		{"success", []string{"pending", "processing", "success"}, "success", false},
+		{"fail", []string{"processing", "fail"}, "fail", true},
+		{"cancelled", []string{"Cancelled"}, "Cancelled", true},
+		{"tts error", []string{"transcribing", "error"}, "error", true},
+	}
+
+	for _, testEl := range testVector {
+
+		t.Run(testEl.name, func(t *testing.T) {
+
+			polls := 0
+			status, failed, err := waitForStatus(context.Background(), 0, func(ctx context.Context) (string, error) {
+
+				polls++
+				return testEl.statuses[polls-1], nil
+			})
+
+			assert.Nil(t, err)
+			assert.Equal(t, testEl.status, status)
+			assert.Equal(t, testEl.failed, failed)
+			assert.Equal(t, len(testEl.statuses), polls)
+		})
+	}
+
+	_, _, err := waitForStatus(context.Background(), 0, func(ctx context.Context) (string, error) {
+
+		return "", errors.New("some error")
+	})
+	assert.EqualError(t, err, "some error")
+
+	status, _, err := waitForStatus(context.Background(), 20*time.Millisecond,
+		func(ctx context.Context) (string, error) {
+
+			return "processing", nil
+		})
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "Timeout")
+	assert.Equal(t, "processing", status)
+}
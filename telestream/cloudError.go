@@ -0,0 +1,298 @@
+package telestream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+
+	"tcs-cli/cli"
+)
+
+// exit codes of tcs commands
+const (
+	ExitOk       = 0
+	ExitFailure  = 1
+	ExitUsage    = 2
+	ExitAuth     = 3
+	ExitNotFound = 4
+	ExitServer   = 5
+	ExitTimeout  = 6
+)
+
+// CommandError - error of client command, holds command name and status code of API response (0 when error
+// did not come from API)
+type CommandError struct {
+	Command    string
+	StatusCode int
+	Err        error
+}
+
+// ApiError - error response of Flip or TTS API decoded from SDK error: status, API message, field validation
+// errors and id of failed request
+type ApiError struct {
+	Status      string
+	StatusCode  int
+	Message     string
+	FieldErrors []FieldError
+	RequestId   string
+}
+
+// FieldError - validation error of single request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// headers which may hold id of request, checked in given order
+var requestIdHeaders = []string{"X-Request-Id", "X-Amzn-Requestid", "X-Amz-Request-Id"}
+
+// TimeoutError - returned when waited resource did not finish in given time
+type TimeoutError struct {
+	message string
+}
+
+func (err *CommandError) Error() string {
+
+	return err.Err.Error()
+}
+
+// Get status, API message and field errors in single line
+func (err *ApiError) Error() string {
+
+	msg := err.Status
+	if err.Message != "" {
+		msg += ": " + err.Message
+	}
+
+	if len(err.FieldErrors) > 0 {
+
+		fields := []string{}
+		for _, fieldErr := range err.FieldErrors {
+			fields = append(fields, fieldErr.String())
+		}
+
+		msg += " (" + strings.Join(fields, "; ") + ")"
+	}
+
+	return msg
+}
+
+func (err FieldError) String() string {
+
+	if err.Field == "" {
+		return err.Message
+	}
+
+	return err.Field + ": " + err.Message
+}
+
+func (err *TimeoutError) Error() string {
+
+	return err.message
+}
+
+// Creates new error of command which failed before or after API call
+func newCommandError(command string, err error) error {
+
+	return &CommandError{Command: command, Err: err}
+}
+
+// Creates new error of command which failed on API call, status code is taken from API response (if any).
+// Returns nil when API call succeeded.
+func newApiError(command string, resp *http.Response, err error) error {
+
+	if err == nil {
+
+		return nil
+	}
+
+	commandErr := &CommandError{Command: command, Err: err}
+
+	if resp != nil {
+
+		commandErr.StatusCode = resp.StatusCode
+	}
+
+	if apiErr := decodeApiError(resp, err); apiErr != nil {
+
+		commandErr.Err = apiErr
+	}
+
+	return commandErr
+}
+
+// Decode API error from SDK error, which holds response status and body as "Status: %v, Body: %s" (response
+// body is already closed). Returns nil when error does not come from API response.
+func decodeApiError(resp *http.Response, err error) *ApiError {
+
+	msg := err.Error()
+	bodyIdx := strings.Index(msg, ", Body: ")
+	if !strings.HasPrefix(msg, "Status: ") || bodyIdx < 0 {
+
+		return nil
+	}
+
+	apiErr := &ApiError{Status: msg[len("Status: "):bodyIdx]}
+	body := strings.TrimSpace(msg[bodyIdx+len(", Body: "):])
+
+	if resp != nil {
+
+		apiErr.Status = resp.Status
+		apiErr.StatusCode = resp.StatusCode
+
+		for _, header := range requestIdHeaders {
+			if requestId := resp.Header.Get(header); requestId != "" {
+
+				apiErr.RequestId = requestId
+				break
+			}
+		}
+	}
+
+	var errorBody struct {
+		Error   string          `json:"error"`
+		Message string          `json:"message"`
+		Errors  json.RawMessage `json:"errors"`
+	}
+
+	if jsonErr := json.Unmarshal([]byte(body), &errorBody); jsonErr != nil {
+
+		// not a json document - body is printed as it is
+		apiErr.Message = body
+		return apiErr
+	}
+
+	apiErr.Message = errorBody.Message
+	if apiErr.Message == "" {
+		apiErr.Message = errorBody.Error
+	}
+
+	apiErr.FieldErrors = decodeFieldErrors(errorBody.Errors)
+
+	return apiErr
+}
+
+// Decode field validation errors given as object (field name to message or list of messages), list of
+// field error objects or list of messages
+func decodeFieldErrors(content json.RawMessage) []FieldError {
+
+	fieldErrors := []FieldError{}
+
+	var fieldMessages map[string]interface{}
+	var errorList []interface{}
+
+	if err := json.Unmarshal(content, &fieldMessages); err == nil {
+
+		for field, messages := range fieldMessages {
+			for _, message := range errorMessages(messages) {
+				fieldErrors = append(fieldErrors, FieldError{Field: field, Message: message})
+			}
+		}
+
+		sort.SliceStable(fieldErrors, func(i, j int) bool { return fieldErrors[i].Field < fieldErrors[j].Field })
+
+	} else if err := json.Unmarshal(content, &errorList); err == nil {
+
+		for _, item := range errorList {
+
+			if object, ok := item.(map[string]interface{}); ok {
+
+				fieldErrors = append(fieldErrors, FieldError{Field: strings.Join(errorMessages(object["field"]), ""),
+					Message: strings.Join(errorMessages(object["message"]), ", ")})
+				continue
+			}
+
+			for _, message := range errorMessages(item) {
+				fieldErrors = append(fieldErrors, FieldError{Message: message})
+			}
+		}
+	}
+
+	if len(fieldErrors) == 0 {
+
+		return nil
+	}
+
+	return fieldErrors
+}
+
+// Get error messages of single field, messages are given as string or list of strings
+func errorMessages(messages interface{}) []string {
+
+	switch value := messages.(type) {
+
+	case nil:
+		return nil
+
+	case string:
+		return []string{value}
+
+	case []interface{}:
+		list := []string{}
+		for _, item := range value {
+			list = append(list, errorMessages(item)...)
+		}
+		return list
+	}
+
+	return []string{fmt.Sprint(messages)}
+}
+
+// Get exit code matching error: usage error, auth failure, not found, API 5xx, timeout or general failure
+func ExitCode(err error) int {
+
+	if err == nil {
+
+		return ExitOk
+	}
+
+	if commandErr, ok := err.(*CommandError); ok {
+
+		switch {
+
+		case commandErr.StatusCode == http.StatusUnauthorized || commandErr.StatusCode == http.StatusForbidden:
+			return ExitAuth
+
+		case commandErr.StatusCode == http.StatusNotFound:
+			return ExitNotFound
+
+		case commandErr.StatusCode >= 500:
+			return ExitServer
+		}
+
+		err = commandErr.Err
+	}
+
+	if cli.IsUsageError(err) {
+
+		return ExitUsage
+	}
+
+	if _, ok := err.(*TimeoutError); ok || err == context.DeadlineExceeded {
+
+		return ExitTimeout
+	}
+
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+
+		return ExitTimeout
+	}
+
+	return ExitFailure
+}
+
+// Print error on output, name of failed command is printed with error of client command
+func PrintError(output ServiceOutput, err error) {
+
+	if commandErr, ok := err.(*CommandError); ok {
+
+		output.printError(commandErr.Command, commandErr.Err)
+		return
+	}
+
+	output.printError("", err)
+}
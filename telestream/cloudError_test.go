@@ -0,0 +1,105 @@
+package telestream
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tcs-cli/cli"
+)
+
+func Test_ExitCode(t *testing.T) {
+
+	var testVector = []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"no error", nil, ExitOk},
+		{"usage", cli.NewUsageError("No flag set"), ExitUsage},
+		{"command usage", newCommandError("ListVideos", cli.NewUsageError("Invalid page: a")), ExitUsage},
+		{"unauthorized", newApiError("ListVideos", &http.Response{StatusCode: 401}, errors.New("401")), ExitAuth},
+		{"forbidden", newApiError("ListVideos", &http.Response{StatusCode: 403}, errors.New("403")), ExitAuth},
+		{"not found", newApiError("DescribeVideo", &http.Response{StatusCode: 404}, errors.New("404")),
+			ExitNotFound},
+		{"server error", newApiError("ListVideos", &http.Response{StatusCode: 502}, errors.New("502")),
+			ExitServer},
+		{"bad request", newApiError("CreateVideo", &http.Response{StatusCode: 422}, errors.New("422")),
+			ExitFailure},
+		{"no response", newApiError("ListVideos", nil, errors.New("connection refused")), ExitFailure},
+		{"wait timeout", newCommandError("WaitVideo", &TimeoutError{"Timeout"}), ExitTimeout},
+		{"request timeout", newApiError("ListVideos", nil, context.DeadlineExceeded), ExitTimeout},
+		{"other error", errors.New("some error"), ExitFailure},
+	}
+
+	for _, testEl := range testVector {
+
+		t.Run(testEl.name, func(t *testing.T) {
+
+			assert.Equal(t, testEl.code, ExitCode(testEl.err))
+		})
+	}
+
+	assert.Nil(t, newApiError("ListVideos", &http.Response{StatusCode: 200}, nil))
+}
+
+func Test_newApiError(t *testing.T) {
+
+	header := http.Header{}
+	header.Set("X-Request-Id", "req-1")
+
+	var testVector = []struct {
+		name string
+		resp *http.Response
+		err  error
+		out  error
+	}{
+		{"validation errors", &http.Response{Status: "422 Unprocessable Entity", StatusCode: 422, Header: header},
+			errors.New(`Status: 422 Unprocessable Entity, Body: {"error":"RecordInvalid",` +
+				`"message":"Validation failed","errors":{"width":["must be positive","is too big"],"name":"is taken"}}`),
+			&ApiError{Status: "422 Unprocessable Entity", StatusCode: 422, Message: "Validation failed",
+				FieldErrors: []FieldError{{"name", "is taken"}, {"width", "must be positive"},
+					{"width", "is too big"}}, RequestId: "req-1"}},
+		{"error list", &http.Response{Status: "400 Bad Request", StatusCode: 400, Header: http.Header{}},
+			errors.New(`Status: 400 Bad Request, Body: {"error":"BadRequest",` +
+				`"errors":[{"field":"profiles","message":"are missing"},"invalid body"]}`),
+			&ApiError{Status: "400 Bad Request", StatusCode: 400, Message: "BadRequest",
+				FieldErrors: []FieldError{{"profiles", "are missing"}, {"", "invalid body"}}}},
+		{"plain body", &http.Response{Status: "502 Bad Gateway", StatusCode: 502, Header: http.Header{}},
+			errors.New("Status: 502 Bad Gateway, Body: <html>bad gateway</html>\n"),
+			&ApiError{Status: "502 Bad Gateway", StatusCode: 502, Message: "<html>bad gateway</html>"}},
+		{"empty body", nil, errors.New("Status: 404 Not Found, Body: "),
+			&ApiError{Status: "404 Not Found"}},
+		{"not api error", nil, errors.New("connection refused"), errors.New("connection refused")},
+	}
+
+	for _, testEl := range testVector {
+
+		t.Run(testEl.name, func(t *testing.T) {
+
+			err := newApiError("Command", testEl.resp, testEl.err)
+			assert.Equal(t, testEl.out, err.(*CommandError).Err)
+		})
+	}
+
+	apiErr := &ApiError{Status: "422 Unprocessable Entity", Message: "Validation failed",
+		FieldErrors: []FieldError{{"name", "is taken"}, {"", "invalid body"}}}
+	assert.Equal(t, "422 Unprocessable Entity: Validation failed (name: is taken; invalid body)", apiErr.Error())
+}
+
+func Test_PrintError(t *testing.T) {
+
+	buffer := new(bytes.Buffer)
+	printer := newTestCsvPrinter(buffer)
+
+	PrintError(printer, newCommandError("ListVideos", errors.New("some error")))
+	assert.Equal(t, "COMMAND,ERROR\nListVideos,some error\n", buffer.String())
+
+	buffer.Reset()
+	PrintError(printer, cli.NewUsageError("No flag set"))
+	assert.Equal(t, "COMMAND,ERROR\n,No flag set\n", buffer.String())
+}
@@ -0,0 +1,627 @@
+package telestream
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"gopkg.in/ini.v1"
+
+	"tcs-cli/cli"
+)
+
+// name of credentials file in user home directory
+const ConfigFileName = ".tcs-credentials"
+
+// profile used when no profile is selected with -profile flag or TCS_PROFILE environment variable
+const DefaultProfile = "default"
+
+// suffix of profile child section which holds http headers
+const headersSection = ".headers"
+
+// environment variable which selects profile
+const ProfileEnv = "TCS_PROFILE"
+
+// environment variable which holds api key, it overrides credentials file
+const ApiKeyEnv = "TCS_API_KEY"
+
+// environment variable which holds path of credentials file used instead of the one in home directory
+const ConfigFileEnv = "TCS_CREDENTIALS_FILE"
+
+// ConfigFile - credentials file, every ini section holds settings of single named profile
+type ConfigFile struct {
+	path string
+	file *ini.File
+}
+
+// Credentials - api key with its source: -api_key flag, TCS_API_KEY environment variable or profile of
+// credentials file
+type Credentials struct {
+	ApiKey  string
+	Source  string
+	Profile string
+	Path    string
+	Store   string
+}
+
+// ProfileSettings - optional settings of profile stored next to its api key. Api key is not loaded with
+// settings, caller sets it to share rate limit of the account.
+type ProfileSettings struct {
+	ApiKey             string            `ini:"-"`
+	FlipEndpoint       string            `ini:"flip_endpoint"`
+	TtsEndpoint        string            `ini:"tts_endpoint"`
+	Headers            map[string]string `ini:"-"`
+	Timeout            string            `ini:"timeout"`
+	Proxy              string            `ini:"proxy"`
+	CaCert             string            `ini:"ca_cert"`
+	InsecureSkipVerify bool              `ini:"insecure_skip_verify"`
+	ClientCert         string            `ini:"client_cert"`
+	ClientKey          string            `ini:"client_key"`
+	Retries            string            `ini:"retries"`
+	RetryMaxWait       string            `ini:"retry_max_wait"`
+	Rps                string            `ini:"rps"`
+}
+
+// ConfigClient - manages profiles stored in credentials file
+type ConfigClient struct {
+	path       string
+	profile    string
+	flagApiKey string
+	output     ServiceOutput
+}
+
+// configCredentials - credentials printed by configure show
+type configCredentials struct {
+	Source  string `json:"source"`
+	Profile string `json:"profile"`
+	Path    string `json:"path"`
+	Store   string `json:"store"`
+	ApiKey  string `json:"api_key"`
+}
+
+// configProfile - profile printed by configure list
+type configProfile struct {
+	Name     string `json:"name"`
+	ApiKey   string `json:"api_key"`
+	Store    string `json:"store"`
+	Selected bool   `json:"selected"`
+}
+
+// Get path of credentials file in user home directory
+func DefaultConfigFilePath() (string, error) {
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+
+		return "", errors.New("Cannot get home directory: " + err.Error())
+	}
+
+	return filepath.Join(home, ConfigFileName), nil
+}
+
+// Get path of credentials file: TCS_CREDENTIALS_FILE environment variable or file in user home directory
+func GetConfigFilePath() (string, error) {
+
+	if envValue := os.Getenv(ConfigFileEnv); envValue != "" {
+
+		return envValue, nil
+	}
+
+	return DefaultConfigFilePath()
+}
+
+// Resolve api key in order: -api_key flag, TCS_API_KEY environment variable, profile of credentials file
+// (path is empty when credentials file cannot be located). Api key is empty when none of sources holds it.
+func ResolveCredentials(flagApiKey string, path string, profile string) (Credentials, error) {
+
+	if flagApiKey != "" {
+
+		return Credentials{ApiKey: flagApiKey, Source: "-api_key flag"}, nil
+	}
+
+	if envValue := os.Getenv(ApiKeyEnv); envValue != "" {
+
+		return Credentials{ApiKey: envValue, Source: ApiKeyEnv + " environment variable"}, nil
+	}
+
+	credentials := Credentials{Source: "credentials file", Profile: profile, Path: path}
+	if os.Getenv(ConfigFileEnv) != "" {
+
+		credentials.Source = ConfigFileEnv + " credentials file"
+	}
+
+	if path == "" {
+
+		return credentials, nil
+	}
+
+	config, err := LoadConfigFile(path)
+	if err != nil {
+
+		return credentials, err
+	}
+
+	credentials.Store = config.Store(profile)
+	credentials.ApiKey, err = config.ReadApiKey(profile)
+
+	return credentials, err
+}
+
+// Check that credentials file is not accessible by other users, missing file is not checked
+func CheckConfigFilePermissions(path string) error {
+
+	fileInfo, err := os.Stat(path)
+	if err != nil || runtime.GOOS == "windows" {
+
+		return nil
+	}
+
+	if fileInfo.Mode().Perm()&0077 != 0 {
+
+		return fmt.Errorf("Configuration file %s is accessible by other users (mode %04o), "+
+			"restrict it with: chmod 600 %s", path, fileInfo.Mode().Perm(), path)
+	}
+
+	return nil
+}
+
+// Load optional settings of profile from credentials file, settings are empty when path is empty or file
+// does not exist
+func LoadProfileSettings(path string, profile string) (ProfileSettings, error) {
+
+	if path == "" {
+
+		return ProfileSettings{}, nil
+	}
+
+	config, err := LoadConfigFile(path)
+	if err != nil {
+
+		return ProfileSettings{}, err
+	}
+
+	return config.Settings(profile)
+}
+
+// Get name of selected profile: -profile flag value, TCS_PROFILE environment variable or default profile
+func GetProfileName(flagValue string) string {
+
+	if flagValue != "" {
+
+		return flagValue
+	}
+
+	if envValue := os.Getenv(ProfileEnv); envValue != "" {
+
+		return envValue
+	}
+
+	return DefaultProfile
+}
+
+// Load credentials file, missing file holds no profiles
+func LoadConfigFile(path string) (*ConfigFile, error) {
+
+	config := new(ConfigFile)
+	config.path = path
+
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+
+		config.file = ini.Empty()
+		return config, nil
+	}
+
+	file, err := ini.Load(path)
+	if err != nil {
+
+		return nil, errors.New("Cannot read configuration file: " + err.Error())
+	}
+
+	config.file = file
+
+	return config, nil
+}
+
+// Get names of all profiles in alphabetical order
+func (config *ConfigFile) Profiles() []string {
+
+	profiles := []string{}
+
+	for _, section := range config.file.Sections() {
+
+		if section.Name() == ini.DEFAULT_SECTION && len(section.Keys()) == 0 {
+			continue
+		}
+
+		// [profile.headers] sections belong to profiles
+		if strings.HasSuffix(section.Name(), headersSection) {
+			continue
+		}
+
+		profiles = append(profiles, section.Name())
+	}
+
+	sort.Strings(profiles)
+
+	return profiles
+}
+
+// Check if profile exists in credentials file
+func (config *ConfigFile) HasProfile(profile string) bool {
+
+	_, err := config.file.GetSection(profile)
+
+	return err == nil
+}
+
+// Get api key of profile, empty when profile does not exist
+func (config *ConfigFile) ApiKey(profile string) string {
+
+	return config.value(profile, "api_key")
+}
+
+// Set api key of profile, profile is created if it does not exist
+func (config *ConfigFile) SetApiKey(profile string, apiKey string) {
+
+	config.file.Section(profile).Key("api_key").SetValue(apiKey)
+}
+
+// Get store of profile api key: plain text credentials file (default), keyring or encrypted credentials file
+func (config *ConfigFile) Store(profile string) string {
+
+	if store := config.value(profile, "store"); store != "" {
+
+		return store
+	}
+
+	return StoreFile
+}
+
+// Read api key of profile from its store, api key is empty when profile does not exist
+func (config *ConfigFile) ReadApiKey(profile string) (string, error) {
+
+	switch store := config.Store(profile); store {
+
+	case StoreFile:
+		return config.ApiKey(profile), nil
+
+	case StoreKeyring:
+		return keyring.Get(profile)
+
+	case StoreEncrypted:
+		return (&encryptedStore{config, passphraseSource}).Get(profile)
+
+	default:
+		return "", errors.New("Unknown api key store of profile " + profile + ": " + store)
+	}
+}
+
+// Save api key of profile in given store, api key kept in previous store is removed. Returns used store -
+// encrypted credentials file replaces keyring when OS keyring is not available.
+func (config *ConfigFile) SaveApiKey(profile string, apiKey string, store string) (string, error) {
+
+	if store == "" {
+		store = StoreFile
+	}
+
+	if store == StoreKeyring && !keyringAvailable() {
+		store = StoreEncrypted
+	}
+
+	previousStore := config.Store(profile)
+
+	switch store {
+
+	case StoreFile:
+		config.SetApiKey(profile, apiKey)
+
+	case StoreKeyring:
+		if err := keyring.Set(profile, apiKey); err != nil {
+			return store, err
+		}
+
+	case StoreEncrypted:
+		if err := (&encryptedStore{config, passphraseSource}).Set(profile, apiKey); err != nil {
+			return store, err
+		}
+
+	default:
+		return store, cli.NewUsageError("Unknown store: " + store + " (file, keyring or encrypted)")
+	}
+
+	if store != StoreFile {
+		config.SetSetting(profile, "api_key", "")
+	}
+
+	if store != StoreEncrypted {
+		config.SetSetting(profile, "api_key_encrypted", "")
+	}
+
+	if previousStore == StoreKeyring && store != StoreKeyring {
+		keyring.Delete(profile)
+	}
+
+	if store == StoreFile {
+		config.SetSetting(profile, "store", "")
+	} else {
+		config.SetSetting(profile, "store", store)
+	}
+
+	return store, nil
+}
+
+// Get optional settings of profile, settings are empty when profile does not exist
+func (config *ConfigFile) Settings(profile string) (ProfileSettings, error) {
+
+	settings := ProfileSettings{}
+
+	section, err := config.file.GetSection(profile)
+	if err != nil {
+
+		return settings, nil
+	}
+
+	if err := section.MapTo(&settings); err != nil {
+
+		return settings, errors.New("Cannot read settings of profile " + profile + ": " + err.Error())
+	}
+
+	if headers, err := config.file.GetSection(profile + headersSection); err == nil {
+
+		settings.Headers = headers.KeysHash()
+	}
+
+	return settings, nil
+}
+
+// Set value of profile setting, empty value removes setting
+func (config *ConfigFile) SetSetting(profile string, key string, value string) {
+
+	if value == "" {
+
+		config.file.Section(profile).DeleteKey(key)
+		return
+	}
+
+	config.file.Section(profile).Key(key).SetValue(value)
+}
+
+// Remove profile with all its settings
+func (config *ConfigFile) RemoveProfile(profile string) error {
+
+	if !config.HasProfile(profile) {
+
+		return errors.New("Profile " + profile + " does not exist")
+	}
+
+	if config.Store(profile) == StoreKeyring {
+		keyring.Delete(profile)
+	}
+
+	config.file.DeleteSection(profile)
+	config.file.DeleteSection(profile + headersSection)
+
+	return nil
+}
+
+// Write credentials file readable by owner only, temporary file is renamed so other profiles are not lost on
+// interrupted write
+func (config *ConfigFile) Save() error {
+
+	buffer := new(bytes.Buffer)
+	if _, err := config.file.WriteTo(buffer); err != nil {
+
+		return errors.New("Cannot save configuration file: " + err.Error())
+	}
+
+	if err := ioutil.WriteFile(config.path+".tmp", buffer.Bytes(), 0600); err != nil {
+
+		return errors.New("Cannot save configuration file: " + err.Error())
+	}
+
+	if err := os.Rename(config.path+".tmp", config.path); err != nil {
+
+		return errors.New("Cannot save configuration file: " + err.Error())
+	}
+
+	return nil
+}
+
+// Get value of profile key, empty when profile or key does not exist
+func (config *ConfigFile) value(profile string, key string) string {
+
+	section, err := config.file.GetSection(profile)
+	if err != nil {
+
+		return ""
+	}
+
+	return section.Key(key).String()
+}
+
+// Mask api key, only its last 4 characters are shown
+func maskApiKey(apiKey string) string {
+
+	if len(apiKey) <= 4 {
+
+		return strings.Repeat("*", len(apiKey))
+	}
+
+	return strings.Repeat("*", len(apiKey)-4) + apiKey[len(apiKey)-4:]
+}
+
+// Creates new config client which manages profiles of given credentials file, profile is the selected one
+// and flagApiKey is value of global -api_key flag
+func NewConfigClient(path string, profile string, flagApiKey string, output ServiceOutput) *ConfigClient {
+
+	client := new(ConfigClient)
+	client.path = path
+	client.profile = profile
+	client.flagApiKey = flagApiKey
+	client.output = output
+
+	return client
+}
+
+// Save api key of selected profile, other profiles are kept
+func (client *ConfigClient) Configure(argsMap cli.FlagMap) error {
+
+	config, err := LoadConfigFile(client.path)
+	if err != nil {
+
+		return newCommandError("Configure", err)
+	}
+
+	// endpoints are changed only when passed, "-" removes endpoint from profile
+	for _, key := range []string{"flip_endpoint", "tts_endpoint"} {
+
+		flag, ok := argsMap[key]
+		if !ok || *flag.Value == "" {
+			continue
+		}
+
+		endpoint := *flag.Value
+
+		if endpoint == "-" {
+
+			config.SetSetting(client.profile, key, "")
+			continue
+		}
+
+		if _, err := parseEndpoint(endpoint); err != nil {
+
+			return newCommandError("Configure", err)
+		}
+
+		config.SetSetting(client.profile, key, endpoint)
+	}
+
+	requestedStore := ""
+	if flag, ok := argsMap["store"]; ok {
+		requestedStore = *flag.Value
+	}
+
+	store, err := config.SaveApiKey(client.profile, *argsMap["api_key"].Value, requestedStore)
+	if err != nil {
+
+		return newCommandError("Configure", err)
+	}
+
+	if err := config.Save(); err != nil {
+
+		return newCommandError("Configure", err)
+	}
+
+	if requestedStore == StoreKeyring && store != StoreKeyring {
+
+		client.output.printInfo("OS keyring is not available, api key is encrypted with passphrase")
+	}
+
+	client.output.printInfo("Credentials of profile " + client.profile + " saved (store: " + store + ")")
+
+	return nil
+}
+
+func (client *ConfigClient) GetConfigureProperties() map[string]bool {
+
+	flagMap := map[string]bool{"api_key": true, "flip_endpoint": false, "tts_endpoint": false, "store": false}
+
+	return flagMap
+}
+
+// Get types of configure flags
+func (client *ConfigClient) GetConfigureFlagTypes() cli.FlagTypes {
+
+	return cli.FlagTypes{"store": {Type: cli.EnumFlag, Choices: []string{StoreFile, StoreKeyring, StoreEncrypted}}}
+}
+
+// List profiles of credentials file with masked api keys, selected profile is marked
+func (client *ConfigClient) ListProfiles() error {
+
+	config, err := LoadConfigFile(client.path)
+	if err != nil {
+
+		return newCommandError("ConfigureList", err)
+	}
+
+	profiles := []configProfile{}
+	rows := [][]interface{}{}
+
+	for _, name := range config.Profiles() {
+
+		// api keys of keyring and encrypted stores are not read, so listing does not ask for passphrase
+		profile := configProfile{name, maskApiKey(config.ApiKey(name)), config.Store(name), name == client.profile}
+		profiles = append(profiles, profile)
+
+		selected := ""
+		if profile.Selected {
+			selected = "*"
+		}
+
+		rows = append(rows, []interface{}{profile.Name, profile.ApiKey, profile.Store, selected})
+	}
+
+	client.output.printCollection(profiles, []interface{}{"PROFILE", "API_KEY", "STORE", "SELECTED"}, rows)
+
+	return nil
+}
+
+// Remove profile given by name from credentials file
+func (client *ConfigClient) RemoveProfile(argsMap cli.FlagMap) error {
+
+	config, err := LoadConfigFile(client.path)
+	if err != nil {
+
+		return newCommandError("ConfigureRemove", err)
+	}
+
+	name := *argsMap["name"].Value
+
+	if err := config.RemoveProfile(name); err != nil {
+
+		return newCommandError("ConfigureRemove", err)
+	}
+
+	if err := config.Save(); err != nil {
+
+		return newCommandError("ConfigureRemove", err)
+	}
+
+	client.output.printInfo("Profile " + name + " removed")
+
+	return nil
+}
+
+func (client *ConfigClient) GetRemoveProfileProperties() map[string]bool {
+
+	flagMap := map[string]bool{"name": true}
+
+	return flagMap
+}
+
+// Show source of api key used by commands (flag, environment variable or profile of credentials file) and
+// masked api key
+func (client *ConfigClient) ShowCredentials() error {
+
+	credentials, err := ResolveCredentials(client.flagApiKey, client.path, client.profile)
+	if err != nil {
+
+		return newCommandError("ConfigureShow", err)
+	}
+
+	if credentials.ApiKey == "" {
+
+		credentials.Source = "none"
+	}
+
+	client.output.printStructContent(configCredentials{credentials.Source, credentials.Profile, credentials.Path,
+		credentials.Store, maskApiKey(credentials.ApiKey)})
+
+	return nil
+}
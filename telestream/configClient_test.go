@@ -0,0 +1,184 @@
+package telestream
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"tcs-cli/cli"
+)
+
+func Test_GetProfileName(t *testing.T) {
+
+	var testVector = []struct {
+		name      string
+		flagValue string
+		envValue  string
+		profile   string
+	}{
+		{"default", "", "", DefaultProfile},
+		{"environment", "", "staging", "staging"},
+		{"flag", "prod", "staging", "prod"},
+	}
+
+	defer os.Setenv(ProfileEnv, os.Getenv(ProfileEnv))
+
+	for _, testEl := range testVector {
+
+		t.Run(testEl.name, func(t *testing.T) {
+
+			os.Setenv(ProfileEnv, testEl.envValue)
+			assert.Equal(t, testEl.profile, GetProfileName(testEl.flagValue))
+		})
+	}
+}
+
+func Test_maskApiKey(t *testing.T) {
+
+	assert.Equal(t, "", maskApiKey(""))
+	assert.Equal(t, "***", maskApiKey("abc"))
+	assert.Equal(t, "******7890", maskApiKey("1234567890"))
+}
+
+func Test_ConfigClient(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "tcs-config")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, ConfigFileName)
+	assert.Nil(t, ioutil.WriteFile(path, []byte("; staging account\n[staging]\napi_key = stagingkey\n"), 0600))
+
+	buffer := new(bytes.Buffer)
+	output := NewServiceToJson(buffer, "")
+
+	apiKey := "prodkey12345"
+	err = NewConfigClient(path, "prod", "", output).Configure(cli.FlagMap{"api_key": {Value: &apiKey, IsRequired: true}})
+	assert.Nil(t, err)
+
+	config, err := LoadConfigFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"prod", "staging"}, config.Profiles())
+	assert.Equal(t, "prodkey12345", config.ApiKey("prod"))
+	assert.Equal(t, "stagingkey", config.ApiKey("staging"))
+	assert.Equal(t, "", config.ApiKey(DefaultProfile))
+
+	content, _ := ioutil.ReadFile(path)
+	assert.Contains(t, string(content), "; staging account")
+
+	buffer.Reset()
+	assert.Nil(t, NewConfigClient(path, "staging", "", output).ListProfiles())
+
+	var profiles []configProfile
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), &profiles))
+	assert.Equal(t, []configProfile{{"prod", "********2345", StoreFile, false},
+		{"staging", "******gkey", StoreFile, true}}, profiles)
+
+	name := "prod"
+	removeFlags := cli.FlagMap{"name": {Value: &name, IsRequired: true}}
+	assert.Nil(t, NewConfigClient(path, DefaultProfile, "", output).RemoveProfile(removeFlags))
+
+	config, _ = LoadConfigFile(path)
+	assert.Equal(t, []string{"staging"}, config.Profiles())
+
+	err = NewConfigClient(path, DefaultProfile, "", output).RemoveProfile(removeFlags)
+	assert.Equal(t, "Profile prod does not exist", err.Error())
+	assert.Equal(t, ExitFailure, ExitCode(err))
+}
+
+func Test_ConfigFile_Settings(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "tcs-config")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, ConfigFileName)
+	content := "[prod]\napi_key = prodkey\nflip_endpoint = http://localhost/flip\ntimeout = 30s\n" +
+		"insecure_skip_verify = true\n" +
+		"[prod.headers]\nX-Tenant = tenant-a\n[staging]\napi_key = stagingkey\n"
+	assert.Nil(t, ioutil.WriteFile(path, []byte(content), 0600))
+
+	config, err := LoadConfigFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"prod", "staging"}, config.Profiles())
+
+	settings, err := config.Settings("prod")
+	assert.Nil(t, err)
+	assert.Equal(t, ProfileSettings{FlipEndpoint: "http://localhost/flip", Timeout: "30s",
+		InsecureSkipVerify: true, Headers: map[string]string{"X-Tenant": "tenant-a"}}, settings)
+
+	settings, err = config.Settings("staging")
+	assert.Nil(t, err)
+	assert.Equal(t, ProfileSettings{}, settings)
+
+	assert.Nil(t, config.RemoveProfile("prod"))
+	assert.False(t, config.HasProfile("prod.headers"))
+}
+
+func Test_LoadConfigFile_missing(t *testing.T) {
+
+	config, err := LoadConfigFile(filepath.Join(os.TempDir(), "tcs-missing-credentials"))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{}, config.Profiles())
+	assert.Equal(t, "", config.ApiKey(DefaultProfile))
+}
+
+func Test_ResolveCredentials(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "tcs-config")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, ConfigFileName)
+	assert.Nil(t, ioutil.WriteFile(path, []byte("[default]\napi_key = filekey\n[prod]\napi_key = prodkey\n"), 0600))
+
+	var testVector = []struct {
+		name        string
+		flagApiKey  string
+		envApiKey   string
+		envPath     string
+		profile     string
+		credentials Credentials
+	}{
+		{"flag", "flagkey", "envkey", path, DefaultProfile, Credentials{ApiKey: "flagkey", Source: "-api_key flag"}},
+		{"environment", "", "envkey", path, DefaultProfile,
+			Credentials{ApiKey: "envkey", Source: "TCS_API_KEY environment variable"}},
+		{"credentials file override", "", "", path, "prod", Credentials{ApiKey: "prodkey",
+			Source: "TCS_CREDENTIALS_FILE credentials file", Profile: "prod", Path: path, Store: StoreFile}},
+		{"credentials file", "", "", "", DefaultProfile, Credentials{ApiKey: "filekey",
+			Source: "credentials file", Profile: DefaultProfile, Path: path, Store: StoreFile}},
+		{"missing profile", "", "", "", "staging", Credentials{Source: "credentials file", Profile: "staging",
+			Path: path, Store: StoreFile}},
+	}
+
+	defer os.Setenv(ApiKeyEnv, os.Getenv(ApiKeyEnv))
+	defer os.Setenv(ConfigFileEnv, os.Getenv(ConfigFileEnv))
+
+	for _, testEl := range testVector {
+
+		t.Run(testEl.name, func(t *testing.T) {
+
+			os.Setenv(ApiKeyEnv, testEl.envApiKey)
+			os.Setenv(ConfigFileEnv, testEl.envPath)
+
+			credentials, err := ResolveCredentials(testEl.flagApiKey, path, testEl.profile)
+			assert.Nil(t, err)
+			assert.Equal(t, testEl.credentials, credentials)
+		})
+	}
+
+	os.Setenv(ApiKeyEnv, "")
+
+	buffer := new(bytes.Buffer)
+	assert.Nil(t, NewConfigClient(path, "prod", "", NewServiceToJson(buffer, "")).ShowCredentials())
+
+	var shown configCredentials
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), &shown))
+	assert.Equal(t, "***dkey", shown.ApiKey)
+	assert.Equal(t, "prod", shown.Profile)
+}
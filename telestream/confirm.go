@@ -0,0 +1,78 @@
+package telestream
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"tcs-cli/cli"
+)
+
+// input and output of delete confirmation prompt and check if prompt can be shown, replaced in tests
+var confirmInput io.Reader = os.Stdin
+var confirmOutput io.Writer = os.Stderr
+var confirmInteractive = func() bool { return isTerminal(os.Stdin) }
+
+// Get -yes (or -force) switch of destructive command from args map and delete both from map
+func getYesOpt(argsMap *cli.FlagMap) (bool, error) {
+
+	yes := false
+
+	for _, name := range []string{"yes", "force"} {
+
+		flagVal, ok := (*argsMap)[name]
+		if !ok {
+			continue
+		}
+
+		delete(*argsMap, name)
+		if *flagVal.Value == "" {
+			continue
+		}
+
+		b, err := strconv.ParseBool(*flagVal.Value)
+		if err != nil {
+
+			return false, cli.NewUsageError("Invalid " + name + ": " + *flagVal.Value)
+		}
+
+		yes = yes || b
+	}
+
+	return yes, nil
+}
+
+// Ask user to confirm deletion by typing id of deleted resource, resource returned by describe is printed
+// before prompt on stderr. Deletion is refused when stdin is not terminal, -yes confirms it in scripts.
+func confirmDelete(command string, kind string, id string,
+	describe func() (interface{}, *http.Response, error)) error {
+
+	if !confirmInteractive() {
+
+		return newCommandError(command, cli.NewUsageError("Deletion of "+kind+" "+id+
+			" is not confirmed, pass -yes to delete it without prompt"))
+	}
+
+	description, resp, err := describe()
+	if err != nil {
+
+		return newApiError(command, resp, err)
+	}
+
+	fmt.Fprintln(confirmOutput, "The following "+kind+" will be deleted:")
+	NewServiceToYaml(confirmOutput).printStructContent(description)
+	fmt.Fprintf(confirmOutput, "Type \"%s\" to confirm deletion of %s: ", id, kind)
+
+	answer, _ := bufio.NewReader(confirmInput).ReadString('\n')
+	if strings.TrimSpace(answer) != id {
+
+		return newCommandError(command, errors.New("Deletion of "+kind+" "+id+" cancelled"))
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+package telestream
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Telestream/telestream-cloud-go-sdk/flip"
+	"github.com/stretchr/testify/assert"
+
+	"tcs-cli/cli"
+)
+
+func Test_FlipClient_DeleteVideo_confirmation(t *testing.T) {
+
+	defer func(interactive func() bool, input io.Reader, output io.Writer) {
+		confirmInteractive, confirmInput, confirmOutput = interactive, input, output
+	}(confirmInteractive, confirmInput, confirmOutput)
+
+	requests := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		requests = append(requests, r.Method+" "+r.URL.Path)
+
+		switch {
+
+		case r.URL.Path == "/videos/missing.json":
+			w.WriteHeader(http.StatusNotFound)
+
+		case r.Method == "GET":
+			json.NewEncoder(w).Encode(flip.Video{Id: "video", OriginalFilename: "movie.mp4"})
+
+		default:
+			json.NewEncoder(w).Encode(flip.DeletedResponse{Deleted: true})
+		}
+	}))
+	defer server.Close()
+
+	var testVector = []struct {
+		name        string
+		interactive bool
+		videoId     string
+		yes         string
+		force       string
+		answer      string
+		code        int
+		requests    []string
+	}{
+		{"no terminal", false, "video", "", "", "", ExitUsage, []string{}},
+		{"yes", false, "video", "true", "", "", ExitOk, []string{"DELETE /videos/video.json"}},
+		{"force", false, "video", "", "true", "", ExitOk, []string{"DELETE /videos/video.json"}},
+		{"confirmed", true, "video", "", "", "video\n", ExitOk,
+			[]string{"GET /videos/video.json", "DELETE /videos/video.json"}},
+		{"wrong id typed", true, "video", "", "", "vid\n", ExitFailure, []string{"GET /videos/video.json"}},
+		{"no answer", true, "video", "", "", "", ExitFailure, []string{"GET /videos/video.json"}},
+		{"describe failed", true, "missing", "", "", "missing\n", ExitNotFound,
+			[]string{"GET /videos/missing.json"}},
+		{"invalid yes", false, "video", "maybe", "", "", ExitUsage, []string{}},
+	}
+
+	for _, testEl := range testVector {
+
+		t.Run(testEl.name, func(t *testing.T) {
+
+			requests = []string{}
+			prompt := new(bytes.Buffer)
+			confirmOutput = prompt
+			confirmInput = strings.NewReader(testEl.answer)
+			confirmInteractive = func() bool { return testEl.interactive }
+
+			client := NewFlipClient("key", "", "", NewServiceToJson(new(bytes.Buffer), ""))
+			client.config.BasePath = server.URL
+
+			factoryId := "factory"
+			argsMap := cli.FlagMap{"factory_id": {Value: &factoryId, IsRequired: true},
+				"video_id": {Value: &testEl.videoId, IsRequired: true}, "yes": {Value: &testEl.yes},
+				"force": {Value: &testEl.force}}
+
+			err := client.DeleteVideo(argsMap)
+
+			assert.Equal(t, testEl.code, ExitCode(err))
+			assert.Equal(t, testEl.requests, requests)
+
+			if testEl.interactive && testEl.code != ExitNotFound {
+				assert.Contains(t, prompt.String(), "The following video will be deleted:\n")
+				assert.Contains(t, prompt.String(), "original_filename: movie.mp4\n")
+				assert.Contains(t, prompt.String(), "Type \"video\" to confirm deletion of video: ")
+			}
+		})
+	}
+}
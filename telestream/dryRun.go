@@ -0,0 +1,121 @@
+package telestream
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"tcs-cli/cli"
+)
+
+// dryRunRequest - API request captured in dry run mode instead of sending it
+type dryRunRequest struct {
+	Method string     `json:"method"`
+	Url    string     `json:"url"`
+	Body   dryRunBody `json:"body,omitempty"`
+}
+
+// dryRunBody - json body of captured request, table output prints it as json
+type dryRunBody map[string]interface{}
+
+// dryRunTransport - captures requests as dryRunRequest errors, requests are never sent
+type dryRunTransport struct{}
+
+func (request *dryRunRequest) Error() string {
+
+	return "dry run of " + request.Method + " " + request.Url
+}
+
+func (body dryRunBody) String() string {
+
+	if len(body) == 0 {
+
+		return ""
+	}
+
+	content, _ := json.Marshal(map[string]interface{}(body))
+
+	return string(content)
+}
+
+func (transport *dryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	request := &dryRunRequest{Method: req.Method, Url: req.URL.String()}
+
+	if req.Body != nil {
+
+		content, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+
+			return nil, err
+		}
+
+		if len(content) > 0 {
+			json.Unmarshal(content, &request.Body)
+		}
+	}
+
+	return nil, request
+}
+
+// Creates http client which captures requests instead of sending them
+func newDryRunClient() *http.Client {
+
+	return &http.Client{Transport: &dryRunTransport{}}
+}
+
+// Replace http client of command with dry run client, returned function restores the configured client, so
+// the client is changed for one command only
+func useDryRunClient(httpClient **http.Client) func() {
+
+	configured := *httpClient
+	*httpClient = newDryRunClient()
+
+	return func() { *httpClient = configured }
+}
+
+// Get dry_run switch from args map and delete it from map
+func getDryRunOpt(argsMap *cli.FlagMap) (bool, error) {
+
+	flagVal, ok := (*argsMap)["dry_run"]
+	if !ok {
+
+		return false, nil
+	}
+
+	delete(*argsMap, "dry_run")
+
+	if *flagVal.Value == "" {
+
+		return false, nil
+	}
+
+	dryRun, err := strconv.ParseBool(*flagVal.Value)
+	if err != nil {
+
+		return false, cli.NewUsageError("Invalid dry_run: " + *flagVal.Value)
+	}
+
+	return dryRun, nil
+}
+
+// Print request captured in dry run mode, returns false when err is not a dry run of request
+func printDryRun(output ServiceOutput, err error) bool {
+
+	if urlErr, ok := err.(*url.Error); ok {
+		err = urlErr.Err
+	}
+
+	request, ok := err.(*dryRunRequest)
+	if !ok {
+
+		return false
+	}
+
+	output.printStructContent(request)
+
+	return true
+}
@@ -2,7 +2,16 @@ package telestream
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"tcs-cli/cli"
 	"github.com/Telestream/telestream-cloud-go-sdk/flip"
@@ -34,17 +43,79 @@ func NewFlipClient(xApiKey string, addHearderKey string, addHeaderVal string, ou
 	return client
 }
 
+// Set base path of flip API (e.g. staging or local mock server), default SDK endpoint is used when empty
+func (client *FlipClient) SetEndpoint(endpoint string) error {
+
+	if endpoint == "" {
+
+		return nil
+	}
+
+	basePath, err := parseEndpoint(endpoint)
+	if err != nil {
+
+		return err
+	}
+
+	client.config.BasePath = basePath
+
+	return nil
+}
+
+// Add http headers sent with every flip API request, keys are canonicalized, so header given in other case
+// replaces earlier one instead of being sent twice
+func (client *FlipClient) SetHeaders(headers map[string]string) {
+
+	for key, val := range headers {
+
+		client.config.AddDefaultHeader(http.CanonicalHeaderKey(key), val)
+	}
+}
+
+// Set http client used for flip API requests
+func (client *FlipClient) SetHttpClient(httpClient *http.Client) {
+
+	client.config.HTTPClient = httpClient
+}
+
+// Get types of flags of flip commands, types of profile and video fields are taken from API bodies, the
+// fields can also be set in -f file
+func (client *FlipClient) GetFlagTypes() cli.FlagTypes {
+
+	return addFileFlagTypes(mergeFlagTypes(commonFlagTypes, structToFlagTypes(&flip.ProfileBody{}),
+		structToFlagTypes(&flip.CreateVideoBody{}), structToFlagTypes(&flip.VideoUploadBody{}),
+		cli.FlagTypes{"concurrency": {Type: cli.IntFlag}, "interval": {Type: cli.DurationFlag},
+			"resume": {Type: cli.BoolFlag}}), &flip.ProfileBody{}, &flip.CreateVideoBody{})
+}
+
 // List all factories to output
-func (client *FlipClient) ListFactories(argsMap cli.FlagMap) {
+func (client *FlipClient) ListFactories(argsMap cli.FlagMap) error {
 
 	opts, pageErr := getPageOpt(&argsMap)
 	if pageErr != nil {
 
-		client.output.printError("ListFactories", pageErr)
-		return
+		return newCommandError("ListFactories", pageErr)
 	}
 
-	factoriesCollection, _, err := client.client.FlipApi.Factories(client.ctx, opts)
+	all, limit, pageErr := getAllPagesOpt(&argsMap)
+	if pageErr != nil {
+
+		return newCommandError("ListFactories", pageErr)
+	}
+
+	factoriesCollection := flip.PaginatedFactoryCollection{}
+
+	err := fetchCollection(opts, all, limit, &factoriesCollection, "Factories",
+		func(opts map[string]interface{}) (interface{}, int, error) {
+
+			pageCollection, resp, err := client.client.FlipApi.Factories(client.ctx, opts)
+			return pageCollection, int(pageCollection.Total), newApiError("ListFactories", resp, err)
+		})
+
+	if err != nil {
+
+		return err
+	}
 
 	storageMap := map[int32]string{0: "S3", 1: "Google Cloud Storage", 2: "FTP storage", 5: "Flip storage",
 		8: "FASP storage", 9: "Azure Blob Storage"}
@@ -52,18 +123,14 @@ func (client *FlipClient) ListFactories(argsMap cli.FlagMap) {
 	colNames := []interface{}{"NAME", "ID", "CREATED_AT", "STORE_ID", "OUTPUT_PATH_FORMAT"}
 	rows := [][]interface{}{}
 
-	if nil == err {
-
-		for _, factory := range factoriesCollection.Factories {
-			rows = append(rows, []interface{}{factory.Name, factory.Id, factory.CreatedAt,
-				storageMap[factory.StorageProvider], factory.OutputsPathFormat})
-		}
+	for _, factory := range factoriesCollection.Factories {
+		rows = append(rows, []interface{}{factory.Name, factory.Id, factory.CreatedAt,
+			storageMap[factory.StorageProvider], factory.OutputsPathFormat})
+	}
 
-		client.output.printTable(colNames, rows)
-	} else {
+	client.output.printCollection(&factoriesCollection, colNames, rows)
 
-		client.output.printError("ListFactories", err)
-	}
+	return nil
 }
 
 // Get list factories input attributes
@@ -76,19 +143,19 @@ func (client *FlipClient) GetListFactoriesProperties() map[string]bool {
 }
 
 // Print factory description on output
-func (client *FlipClient) DescribeFactory(argsMap cli.FlagMap) {
+func (client *FlipClient) DescribeFactory(argsMap cli.FlagMap) error {
 
-	factoryDesc, _, err := client.client.FlipApi.Factory(client.ctx, *argsMap["factory_id"].Value,
+	factoryDesc, resp, err := client.client.FlipApi.Factory(client.ctx, *argsMap["factory_id"].Value,
 		map[string]interface{}{})
 
-	if nil == err {
+	if err != nil {
 
-		client.output.printStructContent(&factoryDesc)
+		return newApiError("DescribeFactory", resp, err)
+	}
 
-	} else {
+	client.output.printStructContent(&factoryDesc)
 
-		client.output.printError("DescribeFatories", err)
-	}
+	return nil
 }
 
 // Get describe factory input attributes
@@ -100,46 +167,58 @@ func (client *FlipClient) GetDescribeFactoryProperties() map[string]bool {
 }
 
 // List all profiles for given factory on output
-func (client *FlipClient) ListProfiles(argsMap cli.FlagMap) {
+func (client *FlipClient) ListProfiles(argsMap cli.FlagMap) error {
 
 	opts, pageErr := getPageOpt(&argsMap)
 	if pageErr != nil {
 
-		client.output.printError("ListProfiles", pageErr)
-		return
+		return newCommandError("ListProfiles", pageErr)
 	}
 
-	profilesCollection, _, err := client.client.FlipApi.Profiles(client.ctx, *argsMap["factory_id"].Value,
-		opts)
+	all, limit, pageErr := getAllPagesOpt(&argsMap)
+	if pageErr != nil {
 
-	colNames := []interface{}{"NAME", " ID", "CREATED_AT", "FORMATS", "SIZE", "VIDEO_BITRATE", "AUDIO_BITRATE"}
-	rows := [][]interface{}{}
+		return newCommandError("ListProfiles", pageErr)
+	}
 
-	if nil == err {
+	profilesCollection := flip.PaginatedProfilesCollection{}
 
-		for _, profile := range profilesCollection.Profiles {
+	err := fetchCollection(opts, all, limit, &profilesCollection, "Profiles",
+		func(opts map[string]interface{}) (interface{}, int, error) {
 
-			vBitrate := fmt.Sprint(profile.VideoBitrate)
-			aBitrate := fmt.Sprint(profile.AudioBitrate)
+			pageCollection, resp, err := client.client.FlipApi.Profiles(client.ctx, *argsMap["factory_id"].Value,
+				opts)
+			return pageCollection, int(pageCollection.Total), newApiError("ListProfiles", resp, err)
+		})
 
-			if "0" == vBitrate {
-				vBitrate = "auto"
-			}
+	if err != nil {
 
-			if "0" == aBitrate {
-				aBitrate = "auto"
-			}
+		return err
+	}
 
-			rows = append(rows, []interface{}{profile.Name, profile.Id, profile.CreatedAt, profile.Title + " " + profile.AudioCodec,
-				fmt.Sprint(profile.Width) + "x" + fmt.Sprint(profile.Height), aBitrate, vBitrate})
-		}
+	colNames := []interface{}{"NAME", " ID", "CREATED_AT", "FORMATS", "SIZE", "VIDEO_BITRATE", "AUDIO_BITRATE"}
+	rows := [][]interface{}{}
+
+	for _, profile := range profilesCollection.Profiles {
+
+		vBitrate := fmt.Sprint(profile.VideoBitrate)
+		aBitrate := fmt.Sprint(profile.AudioBitrate)
 
-		client.output.printTable(colNames, rows)
+		if "0" == vBitrate {
+			vBitrate = "auto"
+		}
 
-	} else {
+		if "0" == aBitrate {
+			aBitrate = "auto"
+		}
 
-		client.output.printError("ListProfiles", err)
+		rows = append(rows, []interface{}{profile.Name, profile.Id, profile.CreatedAt, profile.Title + " " + profile.AudioCodec,
+			fmt.Sprint(profile.Width) + "x" + fmt.Sprint(profile.Height), aBitrate, vBitrate})
 	}
+
+	client.output.printCollection(&profilesCollection, colNames, rows)
+
+	return nil
 }
 
 // Get list profile input attributes
@@ -152,7 +231,7 @@ func (client *FlipClient) GetListProfilesProperties() map[string]bool {
 }
 
 // Print profile given by factory_id and profie_id/profile_name description
-func (client *FlipClient) DescribeProfile(argsMap cli.FlagMap) {
+func (client *FlipClient) DescribeProfile(argsMap cli.FlagMap) error {
 
 	id_or_name := ""
 
@@ -164,24 +243,21 @@ func (client *FlipClient) DescribeProfile(argsMap cli.FlagMap) {
 		id_or_name = *val.Value
 	}
 
-	if id_or_name != "" {
-
-		profile, _, err := client.client.FlipApi.Profile(client.ctx, id_or_name, *argsMap["factory_id"].Value,
-			map[string]interface{}{})
-
-		if nil == err {
+	if id_or_name == "" {
 
-			client.output.printStructContent(&profile)
+		return newCommandError("DescribeProfile", cli.NewUsageError("no profile_id or profile_name"))
+	}
 
-		} else {
+	profile, resp, err := client.client.FlipApi.Profile(client.ctx, id_or_name, *argsMap["factory_id"].Value,
+		map[string]interface{}{})
+	if err != nil {
 
-			client.output.printError("DescribeProfile", err)
-		}
+		return newApiError("DescribeProfile", resp, err)
+	}
 
-	} else {
+	client.output.printStructContent(&profile)
 
-		client.output.printInfo("DescribeProfile: no profile_id or profile_name")
-	}
+	return nil
 }
 
 // Get describe profile by name input attributes
@@ -193,25 +269,42 @@ func (client *FlipClient) GetDescribeProfileProperties() map[string]bool {
 }
 
 // Create new profile in factory (selected by factory_id), print new profile description on output
-func (client *FlipClient) CreateProfile(argsMap cli.FlagMap) {
+func (client *FlipClient) CreateProfile(argsMap cli.FlagMap) error {
+
+	dryRun, err := getDryRunOpt(&argsMap)
+	if err != nil {
+
+		return newCommandError("CreateProfile", err)
+	}
+
+	if dryRun {
+		defer useDryRunClient(&client.config.HTTPClient)()
+	}
 
 	factory_id := *argsMap["factory_id"].Value
 	delete(argsMap, "factory_id")
 
 	newProfile := flip.ProfileBody{}
-	propertiesToStruct(&newProfile, argsMap)
+	if err := readRequestBody(&newProfile, &argsMap); err != nil {
 
-	profileDesc, _, err := client.client.FlipApi.CreateProfile(client.ctx, factory_id, newProfile,
-		map[string]interface{}{})
+		return newCommandError("CreateProfile", err)
+	}
 
-	if nil == err {
+	profileDesc, resp, err := client.client.FlipApi.CreateProfile(client.ctx, factory_id, newProfile,
+		map[string]interface{}{})
 
-		client.output.printStructContent(&profileDesc)
+	if err != nil {
 
-	} else {
+		if printDryRun(client.output, err) {
+			return nil
+		}
 
-		client.output.printError("CreateProfile", err)
+		return newApiError("CreateProfile", resp, err)
 	}
+
+	client.output.printStructContent(&profileDesc)
+
+	return nil
 }
 
 // Get new profile all input attributes
@@ -228,41 +321,90 @@ func (client *FlipClient) GetCreateProfileProperties() map[string]bool {
 	}
 
 	flagMap["preset_name"] = true
+	flagMap["dry_run"] = false
+	addFileOpt(flagMap)
 
 	return flagMap
 }
 
 // Delete profile given by factory_id and profile_id, print result on output
-func (client *FlipClient) DeleteProfile(argsMap cli.FlagMap) {
+func (client *FlipClient) DeleteProfile(argsMap cli.FlagMap) error {
+
+	dryRun, err := getDryRunOpt(&argsMap)
+	if err != nil {
+
+		return newCommandError("DeleteProfile", err)
+	}
+
+	if dryRun {
+		defer useDryRunClient(&client.config.HTTPClient)()
+	}
 
 	factory_id := *argsMap["factory_id"].Value
 	id := *argsMap["profile_id"].Value
 
+	yes, err := getYesOpt(&argsMap)
+	if err != nil {
+
+		return newCommandError("DeleteProfile", err)
+	}
+
+	if !yes && !dryRun {
+
+		err = confirmDelete("DeleteProfile", "profile", id, func() (interface{}, *http.Response, error) {
+
+			profile, resp, err := client.client.FlipApi.Profile(client.ctx, id, factory_id, map[string]interface{}{})
+			return &profile, resp, err
+		})
+		if err != nil {
+
+			return err
+		}
+	}
+
 	newProfile := flip.ProfileBody{}
-	propertiesToStruct(&newProfile, argsMap)
+	if err := propertiesToStruct(&newProfile, argsMap); err != nil {
 
-	profileDel, _, err := client.client.FlipApi.DeleteProfile(client.ctx, id, factory_id)
+		return newCommandError("DeleteProfile", err)
+	}
 
-	if nil == err {
+	profileDel, resp, err := client.client.FlipApi.DeleteProfile(client.ctx, id, factory_id)
 
-		client.output.printStructContent(&profileDel)
+	if err != nil {
 
-	} else {
+		if printDryRun(client.output, err) {
+			return nil
+		}
 
-		client.output.printError("DeleteProfile", err)
+		return newApiError("DeleteProfile", resp, err)
 	}
+
+	client.output.printStructContent(&profileDel)
+
+	return nil
 }
 
 // Get delete attribute input arguments
 func (client *FlipClient) GetDeleteProfileProperties() map[string]bool {
 
-	flagMap := map[string]bool{"factory_id": true, "profile_id": true}
+	flagMap := map[string]bool{"factory_id": true, "profile_id": true, "dry_run": false, "yes": false,
+		"force": false}
 
 	return flagMap
 }
 
 // Update profile and print updated profile description
-func (client *FlipClient) UpdateProfile(argsMap cli.FlagMap) {
+func (client *FlipClient) UpdateProfile(argsMap cli.FlagMap) error {
+
+	dryRun, err := getDryRunOpt(&argsMap)
+	if err != nil {
+
+		return newCommandError("UpdateProfile", err)
+	}
+
+	if dryRun {
+		defer useDryRunClient(&client.config.HTTPClient)()
+	}
 
 	factory_id := *argsMap["factory_id"].Value
 	id := *argsMap["profile_id"].Value
@@ -270,25 +412,32 @@ func (client *FlipClient) UpdateProfile(argsMap cli.FlagMap) {
 	delete(argsMap, "profile_id")
 
 	newProfile := flip.ProfileBody{}
-	propertiesToStruct(&newProfile, argsMap)
+	if err := readRequestBody(&newProfile, &argsMap); err != nil {
 
-	profileDesc, _, err := client.client.FlipApi.UpdateProfile(client.ctx, id, factory_id, newProfile,
-		map[string]interface{}{})
+		return newCommandError("UpdateProfile", err)
+	}
 
-	if nil == err {
+	profileDesc, resp, err := client.client.FlipApi.UpdateProfile(client.ctx, id, factory_id, newProfile,
+		map[string]interface{}{})
 
-		client.output.printStructContent(&profileDesc)
+	if err != nil {
 
-	} else {
+		if printDryRun(client.output, err) {
+			return nil
+		}
 
-		client.output.printError("UpdateProfile", err)
+		return newApiError("UpdateProfile", resp, err)
 	}
+
+	client.output.printStructContent(&profileDesc)
+
+	return nil
 }
 
 // Get update profile all input arguments
 func (client *FlipClient) GetUpdateProfileProperties() map[string]bool {
 
-	flagMap := map[string]bool{"factory_id": true, "profile_id": true}
+	flagMap := map[string]bool{"factory_id": true, "profile_id": true, "dry_run": false}
 	profile := flip.ProfileBody{}
 
 	jsonFields := structToProperties(&profile)
@@ -298,49 +447,64 @@ func (client *FlipClient) GetUpdateProfileProperties() map[string]bool {
 		flagMap[field] = false
 	}
 
+	addFileOpt(flagMap)
+
 	return flagMap
 }
 
 // List all videos in factory (given by factory_id) on output
-func (client *FlipClient) ListVideos(argsMap cli.FlagMap) {
+func (client *FlipClient) ListVideos(argsMap cli.FlagMap) error {
 
 	opts, pageErr := getPageOpt(&argsMap)
 	if pageErr != nil {
 
-		client.output.printError("ListVideos", pageErr)
-		return
+		return newCommandError("ListVideos", pageErr)
 	}
 
-	videosCollection, _, err := client.client.FlipApi.Videos(client.ctx, *argsMap["factory_id"].Value,
-		opts)
+	all, limit, pageErr := getAllPagesOpt(&argsMap)
+	if pageErr != nil {
 
-	colNames := []interface{}{"ORIGINAL_NAME", " ID", "CREATED_AT", "STATUS", "VIDEO_BITRATE", "AUDIO_BITRATE"}
-	rows := [][]interface{}{}
+		return newCommandError("ListVideos", pageErr)
+	}
 
-	if nil == err {
+	videosCollection := flip.PaginatedVideoCollection{}
 
-		for _, video := range videosCollection.Videos {
+	err := fetchCollection(opts, all, limit, &videosCollection, "Videos",
+		func(opts map[string]interface{}) (interface{}, int, error) {
 
-			vBitrate := fmt.Sprint(video.VideoBitrate)
-			aBitrate := fmt.Sprint(video.AudioBitrate)
+			pageCollection, resp, err := client.client.FlipApi.Videos(client.ctx, *argsMap["factory_id"].Value,
+				opts)
+			return pageCollection, int(pageCollection.Total), newApiError("ListVideos", resp, err)
+		})
 
-			if "0" == vBitrate {
-				vBitrate = "auto"
-			}
+	if err != nil {
 
-			if "0" == aBitrate {
-				aBitrate = "auto"
-			}
+		return err
+	}
+
+	colNames := []interface{}{"ORIGINAL_NAME", " ID", "CREATED_AT", "STATUS", "VIDEO_BITRATE", "AUDIO_BITRATE"}
+	rows := [][]interface{}{}
+
+	for _, video := range videosCollection.Videos {
+
+		vBitrate := fmt.Sprint(video.VideoBitrate)
+		aBitrate := fmt.Sprint(video.AudioBitrate)
 
-			rows = append(rows, []interface{}{video.OriginalFilename, video.Id, video.CreatedAt,
-				video.Status, aBitrate, aBitrate})
+		if "0" == vBitrate {
+			vBitrate = "auto"
 		}
 
-		client.output.printTable(colNames, rows)
-	} else {
+		if "0" == aBitrate {
+			aBitrate = "auto"
+		}
 
-		client.output.printError("ListVideos", err)
+		rows = append(rows, []interface{}{video.OriginalFilename, video.Id, video.CreatedAt,
+			video.Status, aBitrate, aBitrate})
 	}
+
+	client.output.printCollection(&videosCollection, colNames, rows)
+
+	return nil
 }
 
 // Get describe video all input attributes
@@ -353,28 +517,31 @@ func (client *FlipClient) GetListVideosProperties() map[string]bool {
 }
 
 // Print video given by factory_id and video_id on output
-func (client *FlipClient) DescribeVideo(argsMap cli.FlagMap) {
+func (client *FlipClient) DescribeVideo(argsMap cli.FlagMap) error {
 
-	video, _, err := client.client.FlipApi.Video(client.ctx, *argsMap["video_id"].Value, *argsMap["factory_id"].Value)
+	video, resp, err := client.client.FlipApi.Video(client.ctx, *argsMap["video_id"].Value, *argsMap["factory_id"].Value)
+	if err != nil {
 
-	encodingsCollection, _, errEnc := client.client.FlipApi.Encodings(client.ctx, *argsMap["factory_id"].Value,
-		map[string]interface{}{"videoId": *argsMap["video_id"].Value})
+		return newApiError("DescribeVideo", resp, err)
+	}
 
-	if nil == err && nil == errEnc {
+	encodingsCollection, resp, err := client.client.FlipApi.Encodings(client.ctx, *argsMap["factory_id"].Value,
+		map[string]interface{}{"videoId": *argsMap["video_id"].Value})
+	if err != nil {
 
-		client.output.printStructContent(&video)
+		return newApiError("DescribeVideo", resp, err)
+	}
 
-		encodingIds := ""
-		for _, encoding := range encodingsCollection.Encodings {
-			encodingIds += encoding.Id + ", "
-		}
+	client.output.printStructContent(&video)
 
-		client.output.printInfo("Encoding ids: " + encodingIds)
+	encodingIds := []string{}
+	for _, encoding := range encodingsCollection.Encodings {
+		encodingIds = append(encodingIds, encoding.Id)
+	}
 
-	} else {
+	client.output.printInfo("Encoding ids: " + strings.Join(encodingIds, ", "))
 
-		client.output.printError("DescribeVideo", err)
-	}
+	return nil
 }
 
 // Get describe video all input attributes
@@ -386,24 +553,41 @@ func (client *FlipClient) GetDescribeVideoProperties() map[string]bool {
 }
 
 // Create new video and print new video description on output
-func (client *FlipClient) CreateVideo(argsMap cli.FlagMap) {
+func (client *FlipClient) CreateVideo(argsMap cli.FlagMap) error {
+
+	dryRun, err := getDryRunOpt(&argsMap)
+	if err != nil {
+
+		return newCommandError("CreateVideo", err)
+	}
+
+	if dryRun {
+		defer useDryRunClient(&client.config.HTTPClient)()
+	}
 
 	factory_id := *argsMap["factory_id"].Value
 	delete(argsMap, "factory_id")
 
 	newVideo := flip.CreateVideoBody{}
-	propertiesToStruct(&newVideo, argsMap)
+	if err := readRequestBody(&newVideo, &argsMap); err != nil {
 
-	videoDesc, _, err := client.client.FlipApi.CreateVideo(client.ctx, factory_id, newVideo)
+		return newCommandError("CreateVideo", err)
+	}
 
-	if nil == err {
+	videoDesc, resp, err := client.client.FlipApi.CreateVideo(client.ctx, factory_id, newVideo)
 
-		client.output.printStructContent(&videoDesc)
+	if err != nil {
 
-	} else {
+		if printDryRun(client.output, err) {
+			return nil
+		}
 
-		client.output.printError("CreateVideo", err)
+		return newApiError("CreateVideo", resp, err)
 	}
+
+	client.output.printStructContent(&videoDesc)
+
+	return nil
 }
 
 // Get create video all input attributes
@@ -420,26 +604,251 @@ func (client *FlipClient) GetCreateVideoProperties() map[string]bool {
 	}
 
 	flagMap["source_url"] = true
+	flagMap["dry_run"] = false
+	addFileOpt(flagMap)
+
+	return flagMap
+}
+
+// Upload local video file (or all files of directory) to factory given by factory_id, uploads are resumed
+// after interruption, print uploaded video (or uploads summary) on output
+func (client *FlipClient) UploadVideo(argsMap cli.FlagMap) error {
+
+	factory_id := *argsMap["factory_id"].Value
+	filePath := *argsMap["file"].Value
+	dirPath := *argsMap["dir"].Value
+	concurrencyStr := *argsMap["concurrency"].Value
+	delete(argsMap, "factory_id")
+	delete(argsMap, "file")
+	delete(argsMap, "dir")
+	delete(argsMap, "concurrency")
+
+	// directory uploads always skip finished files, single file only with -resume
+	resume := dirPath != ""
+	if resumeVal, ok := argsMap["resume"]; ok {
+
+		delete(argsMap, "resume")
+
+		if *resumeVal.Value != "" {
+
+			resumeSet, err := strconv.ParseBool(*resumeVal.Value)
+			if err != nil {
+
+				return newCommandError("UploadVideo", cli.NewUsageError("Invalid resume: "+*resumeVal.Value))
+			}
+
+			resume = resume || resumeSet
+		}
+	}
+
+	if (filePath == "") == (dirPath == "") {
+
+		return newCommandError("UploadVideo", cli.NewUsageError("Exactly one of -file and -dir flags is required"))
+	}
+
+	concurrency := uploadConcurrency
+	if concurrencyStr != "" {
+
+		var err error
+		concurrency, err = strconv.Atoi(concurrencyStr)
+		if err != nil || concurrency <= 0 {
+
+			return newCommandError("UploadVideo", cli.NewUsageError("Invalid concurrency: "+concurrencyStr))
+		}
+	}
+
+	paths := []string{filePath}
+
+	if dirPath != "" {
+
+		files, err := ioutil.ReadDir(dirPath)
+		if err != nil {
+
+			return newCommandError("UploadVideo", err)
+		}
+
+		paths = []string{}
+		for _, file := range files {
+
+			// hidden files are not uploaded
+			if file.Mode().IsRegular() && !strings.HasPrefix(file.Name(), ".") {
+
+				paths = append(paths, filepath.Join(dirPath, file.Name()))
+			}
+		}
+
+		if len(paths) == 0 {
+
+			return newCommandError("UploadVideo", errors.New("No files to upload in "+dirPath))
+		}
+	}
+
+	uploadBody := flip.VideoUploadBody{}
+	if err := propertiesToStruct(&uploadBody, argsMap); err != nil {
+
+		return newCommandError("UploadVideo", err)
+	}
+
+	// interrupted upload stops, its state is kept for next run
+	ctx, cancel := context.WithCancel(client.ctx)
+	defer cancel()
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+
+	go func() {
+
+		select {
+		case <-interrupt:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	results := client.uploadFiles(ctx, factory_id, paths, uploadBody, concurrency, resume)
+
+	if dirPath != "" {
+
+		colNames := []interface{}{"FILE", "STATUS", "VIDEO_ID", "ERROR"}
+		rows := [][]interface{}{}
+
+		for _, result := range results {
+
+			rows = append(rows, []interface{}{result.File, result.Status, result.VideoId, result.Error})
+		}
+
+		client.output.printCollection(&results, colNames, rows)
+
+		failed := 0
+		for _, result := range results {
+			if result.err != nil {
+				failed++
+			}
+		}
+
+		if failed > 0 {
+
+			return newCommandError("UploadVideo", fmt.Errorf("%d of %d uploads not finished", failed, len(results)))
+		}
+
+		return nil
+	}
+
+	if results[0].err != nil {
+
+		if _, ok := results[0].err.(*CommandError); ok {
+
+			return results[0].err
+		}
+
+		return newCommandError("UploadVideo", results[0].err)
+	}
+
+	if results[0].VideoId == "" {
+
+		client.output.printInfo("Video uploaded: " + results[0].File)
+		return nil
+	}
+
+	video, resp, err := client.client.FlipApi.Video(client.ctx, results[0].VideoId, factory_id)
+	if err != nil {
+
+		return newApiError("UploadVideo", resp, err)
+	}
+
+	client.output.printStructContent(&video)
+
+	return nil
+}
+
+// Get upload video all input attributes
+func (client *FlipClient) GetUploadVideoProperties() map[string]bool {
+
+	flagMap := map[string]bool{"factory_id": true, "file": false, "dir": false, "concurrency": false,
+		"resume": false}
+	video := flip.VideoUploadBody{}
+
+	jsonFields := structToProperties(&video)
+
+	for _, field := range jsonFields {
+
+		flagMap[field] = false
+	}
+
+	// set on the basis of uploaded file
+	delete(flagMap, "file_name")
+	delete(flagMap, "file_size")
+	delete(flagMap, "multi_chunk")
+
+	return flagMap
+}
+
+// Wait until video given by factory_id and video_id is processed (or timeout elapses), print video on output
+func (client *FlipClient) WaitVideo(argsMap cli.FlagMap) error {
+
+	timeout, err := getWaitTimeout(&argsMap)
+	if err != nil {
+
+		return newCommandError("WaitVideo", err)
+	}
+
+	video := flip.Video{}
+
+	status, failed, err := waitForStatus(client.ctx, timeout, func(ctx context.Context) (string, error) {
+
+		var resp *http.Response
+		var err error
+		video, resp, err = client.client.FlipApi.Video(ctx, *argsMap["video_id"].Value, *argsMap["factory_id"].Value)
+
+		return video.Status, newApiError("WaitVideo", resp, err)
+	})
+
+	if err != nil {
+
+		if _, ok := err.(*CommandError); ok {
+
+			return err
+		}
+
+		return newCommandError("WaitVideo", err)
+	}
+
+	client.output.printStructContent(&video)
+
+	if failed {
+
+		return newCommandError("WaitVideo", errors.New("Video finished with status "+status))
+	}
+
+	return nil
+}
+
+// Get wait video input attributes
+func (client *FlipClient) GetWaitVideoProperties() map[string]bool {
+
+	flagMap := map[string]bool{"factory_id": true, "video_id": true, "timeout": false}
 
 	return flagMap
 }
 
 // Cancel video given by factory_id and video_id, print result on output
-func (client *FlipClient) CancelVideo(argsMap cli.FlagMap) {
+func (client *FlipClient) CancelVideo(argsMap cli.FlagMap) error {
 
 	factory_id := *argsMap["factory_id"].Value
 	id := *argsMap["video_id"].Value
 
-	videoCancel, _, err := client.client.FlipApi.CancelVideo(client.ctx, id, factory_id)
+	// cancelling cancelled video has no effect, so request is repeated on transient failure
+	videoCancel, resp, err := client.client.FlipApi.CancelVideo(withSafeRetry(client.ctx), id, factory_id)
 
-	if nil == err {
+	if err != nil {
 
-		client.output.printStructContent(&videoCancel)
+		return newApiError("CancelVideo", resp, err)
+	}
 
-	} else {
+	client.output.printStructContent(&videoCancel)
 
-		client.output.printError("CancelVideo", err)
-	}
+	return nil
 }
 
 // Get cancel video input attributes
@@ -451,39 +860,78 @@ func (client *FlipClient) GetCancelVideoProperties() map[string]bool {
 }
 
 // Delete video given by factory_id and video_id and print result
-func (client *FlipClient) DeleteVideo(argsMap cli.FlagMap) {
+func (client *FlipClient) DeleteVideo(argsMap cli.FlagMap) error {
+
+	dryRun, err := getDryRunOpt(&argsMap)
+	if err != nil {
+
+		return newCommandError("DeleteVideo", err)
+	}
+
+	if dryRun {
+		defer useDryRunClient(&client.config.HTTPClient)()
+	}
 
 	factory_id := *argsMap["factory_id"].Value
 	id := *argsMap["video_id"].Value
 
-	videoDelete, _, err := client.client.FlipApi.DeleteVideo(client.ctx, id, factory_id)
+	yes, err := getYesOpt(&argsMap)
+	if err != nil {
+
+		return newCommandError("DeleteVideo", err)
+	}
+
+	if !yes && !dryRun {
+
+		err = confirmDelete("DeleteVideo", "video", id, func() (interface{}, *http.Response, error) {
+
+			video, resp, err := client.client.FlipApi.Video(client.ctx, id, factory_id)
+			return &video, resp, err
+		})
+		if err != nil {
+
+			return err
+		}
+	}
 
-	if nil == err {
+	videoDelete, resp, err := client.client.FlipApi.DeleteVideo(client.ctx, id, factory_id)
 
-		client.output.printStructContent(&videoDelete)
+	if err != nil {
 
-	} else {
+		if printDryRun(client.output, err) {
+			return nil
+		}
 
-		client.output.printError("DeleteVideo", err)
+		return newApiError("DeleteVideo", resp, err)
 	}
+
+	client.output.printStructContent(&videoDelete)
+
+	return nil
 }
 
 // Get delete video input attributes
 func (client *FlipClient) GetDeleteVideoProperties() map[string]bool {
 
-	flagMap := map[string]bool{"factory_id": true, "video_id": true}
+	flagMap := map[string]bool{"factory_id": true, "video_id": true, "dry_run": false, "yes": false,
+		"force": false}
 
 	return flagMap
 }
 
 // List encodings for given factory_id (and video_id - optional)
-func (client *FlipClient) ListEncodings(argsMap cli.FlagMap) {
+func (client *FlipClient) ListEncodings(argsMap cli.FlagMap) error {
 
 	opts, pageErr := getPageOpt(&argsMap)
 	if pageErr != nil {
 
-		client.output.printError("ListEncodings", pageErr)
-		return
+		return newCommandError("ListEncodings", pageErr)
+	}
+
+	all, limit, pageErr := getAllPagesOpt(&argsMap)
+	if pageErr != nil {
+
+		return newCommandError("ListEncodings", pageErr)
 	}
 
 	factory_id := *argsMap["factory_id"].Value
@@ -494,23 +942,24 @@ func (client *FlipClient) ListEncodings(argsMap cli.FlagMap) {
 		opts["videoId"] = *argsMap["video_id"].Value
 	}
 
-	encodingsCollection, _, err := client.client.FlipApi.Encodings(client.ctx, factory_id, opts)
+	encodingsCollection, err := client.fetchEncodings("ListEncodings", factory_id, opts, all, limit)
 
 	colNames := []interface{}{"ID", "CREATED_AT", "STATUS", "FILE_SIZE", "VIDEO_ID"}
 	rows := [][]interface{}{}
 
-	if nil == err {
+	if err != nil {
 
-		for _, encoding := range encodingsCollection.Encodings {
-
-			rows = append(rows, []interface{}{encoding.Id, encoding.CreatedAt, encoding.Status, fmt.Sprint(encoding.FileSize), encoding.VideoId})
-		}
+		return err
+	}
 
-		client.output.printTable(colNames, rows)
-	} else {
+	for _, encoding := range encodingsCollection.Encodings {
 
-		client.output.printError("ListEncodings", err)
+		rows = append(rows, []interface{}{encoding.Id, encoding.CreatedAt, encoding.Status, fmt.Sprint(encoding.FileSize), encoding.VideoId})
 	}
+
+	client.output.printCollection(&encodingsCollection, colNames, rows)
+
+	return nil
 }
 
 // Get list encodings inout attributes
@@ -522,19 +971,98 @@ func (client *FlipClient) GetListEncodingsProperties() map[string]bool {
 	return flagMap
 }
 
+// Fetch encodings of factory (all pages or only page given in opts), collection is cut to limit (if set).
+// Error is returned as error of given command.
+func (client *FlipClient) fetchEncodings(command string, factoryId string, opts map[string]interface{}, all bool,
+	limit int) (flip.PaginatedEncodingsCollection, error) {
+
+	encodingsCollection := flip.PaginatedEncodingsCollection{}
+
+	err := fetchCollection(opts, all, limit, &encodingsCollection, "Encodings",
+		func(opts map[string]interface{}) (interface{}, int, error) {
+
+			pageCollection, resp, err := client.client.FlipApi.Encodings(client.ctx, factoryId, opts)
+			return pageCollection, int(pageCollection.Total), newApiError(command, resp, err)
+		})
+
+	return encodingsCollection, err
+}
+
+// Watch encodings of given factory_id (and video_id - optional), table with encodings progress is refreshed
+// every interval until all encodings are finished
+func (client *FlipClient) WatchEncodings(argsMap cli.FlagMap) error {
+
+	interval := watchInterval
+
+	if *argsMap["interval"].Value != "" {
+
+		var err error
+		interval, err = parseDuration(*argsMap["interval"].Value)
+		if err != nil {
+
+			return newCommandError("WatchEncodings", err)
+		}
+
+		if interval < watchMinInterval {
+
+			return newCommandError("WatchEncodings", cli.NewUsageError("Interval must be at least "+
+				watchMinInterval.String()+": "+*argsMap["interval"].Value))
+		}
+	}
+
+	watcher := newEncodingsWatcher(watchOutput, watchRedraw)
+
+	for {
+
+		opts := map[string]interface{}{}
+		if *argsMap["video_id"].Value != "" {
+
+			opts["videoId"] = *argsMap["video_id"].Value
+		}
+
+		encodingsCollection, err := client.fetchEncodings("WatchEncodings", *argsMap["factory_id"].Value, opts,
+			true, 0)
+		if err != nil {
+
+			return err
+		}
+
+		watcher.draw(encodingsCollection.Encodings, time.Now())
+
+		if allEncodingsFinished(encodingsCollection.Encodings) {
+
+			return nil
+		}
+
+		if err := sleepContext(client.ctx, interval); err != nil {
+
+			return newCommandError("WatchEncodings", err)
+		}
+	}
+}
+
+// Get watch encodings input attributes
+func (client *FlipClient) GetWatchEncodingsProperties() map[string]bool {
+
+	flagMap := map[string]bool{"factory_id": true, "video_id": false, "interval": false}
+
+	return flagMap
+}
+
 // Print encoding description given by factory_id and encoding_id
-func (client *FlipClient) DescribeEncoding(argsMap cli.FlagMap) {
+func (client *FlipClient) DescribeEncoding(argsMap cli.FlagMap) error {
 
-	encoding, _, err := client.client.FlipApi.Encoding(client.ctx, *argsMap["encoding_id"].Value,
+	encoding, resp, err := client.client.FlipApi.Encoding(client.ctx, *argsMap["encoding_id"].Value,
 		*argsMap["factory_id"].Value, map[string]interface{}{})
 
-	if nil == err {
-
-		client.output.printStructContent(&encoding)
-	} else {
+	if err != nil {
 
-		client.output.printError("DescribeVideos", err)
+		return newApiError("DescribeEncoding", resp, err)
 	}
+
+	client.output.printStructContent(&encoding)
+
+	return nil
 }
 
 // Get describe encoding input attributes
@@ -545,42 +1073,115 @@ func (client *FlipClient) GetDescribeEncodingProperties() map[string]bool {
 	return flagMap
 }
 
+// Wait until encoding given by factory_id and encoding_id is finished (or timeout elapses), print encoding
+// on output
+func (client *FlipClient) WaitEncoding(argsMap cli.FlagMap) error {
+
+	timeout, err := getWaitTimeout(&argsMap)
+	if err != nil {
+
+		return newCommandError("WaitEncoding", err)
+	}
+
+	encoding := flip.Encoding{}
+
+	status, failed, err := waitForStatus(client.ctx, timeout, func(ctx context.Context) (string, error) {
+
+		var resp *http.Response
+		var err error
+		encoding, resp, err = client.client.FlipApi.Encoding(ctx, *argsMap["encoding_id"].Value,
+			*argsMap["factory_id"].Value, map[string]interface{}{})
+
+		return encoding.Status, newApiError("WaitEncoding", resp, err)
+	})
+
+	if err != nil {
+
+		if _, ok := err.(*CommandError); ok {
+
+			return err
+		}
+
+		return newCommandError("WaitEncoding", err)
+	}
+
+	client.output.printStructContent(&encoding)
+
+	if failed {
+
+		return newCommandError("WaitEncoding", errors.New("Encoding finished with status "+status))
+	}
+
+	return nil
+}
+
+// Get wait encoding input attributes
+func (client *FlipClient) GetWaitEncodingProperties() map[string]bool {
+
+	flagMap := map[string]bool{"factory_id": true, "encoding_id": true, "timeout": false}
+
+	return flagMap
+}
+
 // Delete encoding given by factory_id an encoding_id, print result on output
-func (client *FlipClient) DeleteEncoding(argsMap cli.FlagMap) {
+func (client *FlipClient) DeleteEncoding(argsMap cli.FlagMap) error {
 
-	deleteEncoding, _, err := client.client.FlipApi.DeleteEncoding(client.ctx, *argsMap["encoding_id"].Value,
-		*argsMap["factory_id"].Value)
+	yes, err := getYesOpt(&argsMap)
+	if err != nil {
+
+		return newCommandError("DeleteEncoding", err)
+	}
+
+	if !yes {
+
+		err = confirmDelete("DeleteEncoding", "encoding", *argsMap["encoding_id"].Value,
+			func() (interface{}, *http.Response, error) {
+
+				encoding, resp, err := client.client.FlipApi.Encoding(client.ctx, *argsMap["encoding_id"].Value,
+					*argsMap["factory_id"].Value, map[string]interface{}{})
+				return &encoding, resp, err
+			})
+		if err != nil {
+
+			return err
+		}
+	}
 
-	if nil == err {
+	deleteEncoding, resp, err := client.client.FlipApi.DeleteEncoding(client.ctx, *argsMap["encoding_id"].Value,
+		*argsMap["factory_id"].Value)
 
-		client.output.printStructContent(&deleteEncoding)
-	} else {
+	if err != nil {
 
-		client.output.printError("DeleteEncoding", err)
+		return newApiError("DeleteEncoding", resp, err)
 	}
+
+	client.output.printStructContent(&deleteEncoding)
+
+	return nil
 }
 
 // Get delete encoding input attributes
 func (client *FlipClient) GetDeleteEncodingProperties() map[string]bool {
 
-	flagMap := map[string]bool{"factory_id": true, "encoding_id": true}
+	flagMap := map[string]bool{"factory_id": true, "encoding_id": true, "yes": false, "force": false}
 
 	return flagMap
 }
 
 // Print signed urls for specific encoding given by factory_id and encoding_id
-func (client *FlipClient) SignedUrlsEncoding(argsMap cli.FlagMap) {
+func (client *FlipClient) SignedUrlsEncoding(argsMap cli.FlagMap) error {
 
-	EncodingSignedUrls, _, err := client.client.FlipApi.SignedEncodingUrls(client.ctx, *argsMap["encoding_id"].Value,
+	EncodingSignedUrls, resp, err := client.client.FlipApi.SignedEncodingUrls(client.ctx, *argsMap["encoding_id"].Value,
 		*argsMap["factory_id"].Value)
 
-	if nil == err {
-
-		client.output.printStructContent(&EncodingSignedUrls)
-	} else {
+	if err != nil {
 
-		client.output.printError("SignedUrlsEncoding", err)
+		return newApiError("SignedUrlsEncoding", resp, err)
 	}
+
+	client.output.printStructContent(&EncodingSignedUrls)
+
+	return nil
 }
 
 // Get signed urls encoding input attributes
@@ -592,18 +1193,20 @@ func (client *FlipClient) GetSignedUrlsEncodingProperties() map[string]bool {
 }
 
 // Cancel encoding specified by factory_id and encoding_id, print result on output
-func (client *FlipClient) CancelEncoding(argsMap cli.FlagMap) {
-
-	cancelEncoding, _, err := client.client.FlipApi.CancelEncoding(client.ctx, *argsMap["encoding_id"].Value,
-		*argsMap["factory_id"].Value)
+func (client *FlipClient) CancelEncoding(argsMap cli.FlagMap) error {
 
-	if nil == err {
+	// cancelling cancelled encoding has no effect, so request is repeated on transient failure
+	cancelEncoding, resp, err := client.client.FlipApi.CancelEncoding(withSafeRetry(client.ctx),
+		*argsMap["encoding_id"].Value, *argsMap["factory_id"].Value)
 
-		client.output.printStructContent(&cancelEncoding)
-	} else {
+	if err != nil {
 
-		client.output.printError("CancelEncoding", err)
+		return newApiError("CancelEncoding", resp, err)
 	}
+
+	client.output.printStructContent(&cancelEncoding)
+
+	return nil
 }
 
 // Get cancel encoding input attributes
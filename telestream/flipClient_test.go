@@ -0,0 +1,187 @@
+package telestream
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Telestream/telestream-cloud-go-sdk/flip"
+	"github.com/stretchr/testify/assert"
+
+	"tcs-cli/cli"
+)
+
+func Test_FlipClient_WaitEncoding(t *testing.T) {
+
+	defer func(minDelay, maxDelay time.Duration) {
+		waitMinDelay, waitMaxDelay = minDelay, maxDelay
+	}(waitMinDelay, waitMaxDelay)
+
+	waitMinDelay = time.Millisecond
+	waitMaxDelay = time.Millisecond
+
+	statuses := []string{"pending", "processing", "success"}
+	polls := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		assert.Equal(t, "/encodings/encoding.json", r.URL.Path)
+		assert.Equal(t, "factory", r.URL.Query().Get("factory_id"))
+
+		status := statuses[len(statuses)-1]
+		if polls < len(statuses) {
+			status = statuses[polls]
+		}
+		polls++
+
+		json.NewEncoder(w).Encode(flip.Encoding{Id: "encoding", Status: status})
+	}))
+	defer server.Close()
+
+	buffer := new(bytes.Buffer)
+	client := NewFlipClient("key", "", "", NewServiceToJson(buffer, ""))
+	client.config.BasePath = server.URL
+
+	factoryId := "factory"
+	encodingId := "encoding"
+	timeout := ""
+	argsMap := cli.FlagMap{"factory_id": {Value: &factoryId, IsRequired: true},
+		"encoding_id": {Value: &encodingId, IsRequired: true}, "timeout": {Value: &timeout}}
+
+	err := client.WaitEncoding(argsMap)
+
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"id": "encoding", "status": "success"}`, buffer.String())
+	assert.Equal(t, 3, polls)
+
+	// failed encoding is reported as error
+	buffer.Reset()
+	statuses = []string{"fail"}
+	polls = 0
+	argsMap["timeout"] = cli.FlagProperties{Value: &timeout}
+	err = client.WaitEncoding(argsMap)
+
+	assert.JSONEq(t, `{"id": "encoding", "status": "fail"}`, buffer.String())
+	assert.Equal(t, ExitFailure, ExitCode(err))
+
+	// encoding which is not finished in given time
+	buffer.Reset()
+	statuses = []string{"processing"}
+	timeout = "50ms"
+	argsMap["timeout"] = cli.FlagProperties{Value: &timeout}
+	err = client.WaitEncoding(argsMap)
+
+	assert.Equal(t, "", buffer.String())
+	assert.Equal(t, ExitTimeout, ExitCode(err))
+}
+
+func Test_FlipClient_apiErrors(t *testing.T) {
+
+	var testVector = []struct {
+		name   string
+		status int
+		code   int
+	}{
+		{"unauthorized", http.StatusUnauthorized, ExitAuth},
+		{"not found", http.StatusNotFound, ExitNotFound},
+		{"server error", http.StatusServiceUnavailable, ExitServer},
+		{"bad request", http.StatusBadRequest, ExitFailure},
+	}
+
+	for _, testEl := range testVector {
+
+		t.Run(testEl.name, func(t *testing.T) {
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+				w.Header().Set("X-Request-Id", "req-1")
+				w.WriteHeader(testEl.status)
+				w.Write([]byte(`{"message":"failed"}`))
+			}))
+			defer server.Close()
+
+			buffer := new(bytes.Buffer)
+			client := NewFlipClient("key", "", "", NewServiceToJson(buffer, ""))
+			client.config.BasePath = server.URL
+
+			factoryId := "factory"
+			videoId := "video"
+			err := client.DescribeVideo(cli.FlagMap{"factory_id": {Value: &factoryId, IsRequired: true},
+				"video_id": {Value: &videoId, IsRequired: true}})
+
+			assert.Equal(t, testEl.code, ExitCode(err))
+			assert.Equal(t, "DescribeVideo", err.(*CommandError).Command)
+			assert.Equal(t, &ApiError{Status: fmt.Sprintf("%d %s", testEl.status, http.StatusText(testEl.status)), StatusCode: testEl.status,
+				Message: "failed", RequestId: "req-1"}, err.(*CommandError).Err)
+			assert.Equal(t, "", buffer.String())
+		})
+	}
+}
+
+func Test_FlipClient_dryRun(t *testing.T) {
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		requests++
+	}))
+	defer server.Close()
+
+	buffer := new(bytes.Buffer)
+	client := NewFlipClient("key", "", "", NewServiceToJson(buffer, ""))
+	client.config.BasePath = server.URL
+	httpClient := client.config.HTTPClient
+
+	factoryId := "factory"
+	presetName := "h264"
+	name := "web"
+	dryRun := "true"
+	argsMap := cli.FlagMap{"factory_id": {Value: &factoryId, IsRequired: true},
+		"preset_name": {Value: &presetName, IsRequired: true}, "name": {Value: &name},
+		"dry_run": {Value: &dryRun}}
+
+	err := client.CreateProfile(argsMap)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, requests)
+	assert.True(t, httpClient == client.config.HTTPClient)
+	assert.JSONEq(t, `{"method": "POST", "url": "`+server.URL+`/profiles.json?factory_id=factory",
+		"body": {"preset_name": "h264", "name": "web"}}`, buffer.String())
+
+	buffer.Reset()
+	videoId := "video"
+	argsMap = cli.FlagMap{"factory_id": {Value: &factoryId, IsRequired: true},
+		"video_id": {Value: &videoId, IsRequired: true}, "dry_run": {Value: &dryRun}}
+
+	err = client.DeleteVideo(argsMap)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, requests)
+	assert.JSONEq(t, `{"method": "DELETE", "url": "`+server.URL+`/videos/video.json?factory_id=factory"}`,
+		buffer.String())
+
+	invalid := "maybe"
+	argsMap = cli.FlagMap{"factory_id": {Value: &factoryId, IsRequired: true},
+		"video_id": {Value: &videoId, IsRequired: true}, "dry_run": {Value: &invalid}}
+
+	assert.Equal(t, ExitUsage, ExitCode(client.DeleteVideo(argsMap)))
+	assert.Equal(t, 0, requests)
+
+	// configured client is used by commands after dry run
+	argsMap = cli.FlagMap{"factory_id": {Value: &factoryId, IsRequired: true},
+		"video_id": {Value: &videoId, IsRequired: true}}
+
+	client.DescribeVideo(argsMap)
+	assert.Equal(t, 1, requests)
+}
+
+func Test_dryRunBody_String(t *testing.T) {
+
+	assert.Equal(t, `{"name":"web","preset_name":"h264"}`,
+		dryRunBody{"preset_name": "h264", "name": "web"}.String())
+	assert.Equal(t, "", dryRunBody(nil).String())
+}
@@ -0,0 +1,231 @@
+package telestream
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Telestream/telestream-cloud-go-sdk/flip"
+)
+
+// Get path of file which holds state of uploads (in user cache directory), replaced in tests
+var uploadStatePath = func() (string, error) {
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+
+		return "", err
+	}
+
+	return filepath.Join(cacheDir, "tcs", "upload-state.json"), nil
+}
+
+// output of warnings about upload state which cannot be loaded or saved, replaced in tests
+var uploadStateWarnings io.Writer = os.Stderr
+
+// uploadStateEntry - upload state of single file: upload session, offsets of uploaded parts and created video
+type uploadStateEntry struct {
+	FactoryId        string             `json:"factory_id"`
+	Size             int64              `json:"size"`
+	ModTime          time.Time          `json:"mod_time"`
+	Session          flip.UploadSession `json:"session"`
+	CompletedOffsets []int64            `json:"completed_offsets"`
+	Finished         bool               `json:"finished"`
+	VideoId          string             `json:"video_id,omitempty"`
+}
+
+// uploadState - state of uploads stored in local file (entries are keyed by absolute paths of uploaded files),
+// it is saved after every uploaded part so interrupted upload can be resumed and finished uploads are skipped.
+// Failed save is only a warning, upload goes on without resume possibility.
+type uploadState struct {
+	mutex    sync.Mutex
+	path     string
+	entries  map[string]*uploadStateEntry
+	warnOnce sync.Once
+}
+
+// Load uploads state from state file, state which cannot be loaded is replaced by empty one with warning
+func openUploadState() *uploadState {
+
+	path, err := uploadStatePath()
+	if err != nil {
+
+		state := &uploadState{entries: map[string]*uploadStateEntry{}}
+		state.warn(err)
+
+		return state
+	}
+
+	state, err := loadUploadState(path)
+	if err != nil {
+
+		state = &uploadState{path: path, entries: map[string]*uploadStateEntry{}}
+		state.warn(err)
+	}
+
+	return state
+}
+
+// Load uploads state from given file, missing file means no uploads were started
+func loadUploadState(path string) (*uploadState, error) {
+
+	state := new(uploadState)
+	state.path = path
+	state.entries = map[string]*uploadStateEntry{}
+
+	content, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+
+		return state, nil
+	}
+
+	if err != nil {
+
+		return nil, err
+	}
+
+	if err := json.Unmarshal(content, &state.entries); err != nil {
+
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// Get upload state of file, state is reset if file or factory changed since upload was started
+func (state *uploadState) entry(name string, factoryId string, fileInfo os.FileInfo) uploadStateEntry {
+
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	entry, ok := state.entries[name]
+	if !ok || entry.FactoryId != factoryId || entry.Size != fileInfo.Size() ||
+		!entry.ModTime.Equal(fileInfo.ModTime()) {
+
+		entry = &uploadStateEntry{FactoryId: factoryId, Size: fileInfo.Size(), ModTime: fileInfo.ModTime()}
+		state.entries[name] = entry
+	}
+
+	return *entry
+}
+
+// Store new upload session of file, offsets uploaded in previous session are dropped
+func (state *uploadState) startSession(name string, session flip.UploadSession) {
+
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	entry := state.entries[name]
+	entry.Session = session
+	entry.CompletedOffsets = nil
+	entry.Finished = false
+	entry.VideoId = ""
+
+	state.save()
+}
+
+// Store offset of uploaded part, returns number of bytes uploaded so far
+func (state *uploadState) completePart(name string, part int) int64 {
+
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	entry := state.entries[name]
+	offset := int64(part) * int64(entry.Session.PartSize)
+
+	idx := sort.Search(len(entry.CompletedOffsets), func(i int) bool { return entry.CompletedOffsets[i] >= offset })
+	if idx == len(entry.CompletedOffsets) || entry.CompletedOffsets[idx] != offset {
+
+		entry.CompletedOffsets = append(entry.CompletedOffsets, 0)
+		copy(entry.CompletedOffsets[idx+1:], entry.CompletedOffsets[idx:])
+		entry.CompletedOffsets[idx] = offset
+
+		state.save()
+	}
+
+	return entry.uploadedBytes()
+}
+
+// Mark upload of file as finished, finished files are skipped by next resumed uploads
+func (state *uploadState) finish(name string, videoId string) {
+
+	state.mutex.Lock()
+	defer state.mutex.Unlock()
+
+	entry := state.entries[name]
+	entry.Finished = true
+	entry.VideoId = videoId
+
+	state.save()
+}
+
+// Write state to file, temporary file is renamed so state is not broken by interrupted write. Failed write is
+// reported once as warning, state is written again with next change.
+func (state *uploadState) save() {
+
+	if state.path == "" {
+
+		return
+	}
+
+	if err := state.write(); err != nil {
+		state.warn(err)
+	}
+}
+
+func (state *uploadState) write() error {
+
+	content, err := json.MarshalIndent(state.entries, "", "  ")
+	if err != nil {
+
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(state.path), 0700); err != nil {
+
+		return err
+	}
+
+	if err := ioutil.WriteFile(state.path+".tmp", content, 0600); err != nil {
+
+		return err
+	}
+
+	return os.Rename(state.path+".tmp", state.path)
+}
+
+// Print warning about state which cannot be loaded or saved, only the first warning is printed
+func (state *uploadState) warn(err error) {
+
+	state.warnOnce.Do(func() {
+
+		fmt.Fprintln(uploadStateWarnings, "Warning: Upload state: "+err.Error()+
+			" (interrupted uploads cannot be resumed)")
+	})
+}
+
+// Count bytes of parts which were uploaded, the last part may be shorter than part size
+func (entry *uploadStateEntry) uploadedBytes() int64 {
+
+	uploaded := int64(0)
+
+	for _, offset := range entry.CompletedOffsets {
+
+		partSize := int64(entry.Session.PartSize)
+		if offset+partSize > entry.Size {
+			partSize = entry.Size - offset
+		}
+
+		if partSize > 0 {
+			uploaded += partSize
+		}
+	}
+
+	return uploaded
+}
@@ -0,0 +1,487 @@
+package telestream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Telestream/telestream-cloud-go-sdk/flip"
+	"github.com/jedib0t/go-pretty/progress"
+)
+
+// number of repeats of failed part upload and delay between them
+var uploadRetries = 5
+var uploadRetryDelay = time.Second
+
+// number of files uploaded in parallel
+var uploadConcurrency = 2
+
+// progress bars of uploaded files are written to terminal only
+var uploadProgressOutput io.Writer
+
+func init() {
+
+	if isTerminal(os.Stderr) {
+
+		uploadProgressOutput = os.Stderr
+	}
+}
+
+// uploadResult - result of single file upload
+type uploadResult struct {
+	File    string `json:"file"`
+	Status  string `json:"status"`
+	VideoId string `json:"video_id"`
+	Error   string `json:"error,omitempty"`
+	err     error
+}
+
+// uploadStatus - status returned by upload session location, holds parts which are still not uploaded
+type uploadStatus struct {
+	MissingParts []int `json:"missing_parts"`
+}
+
+// chunkUploader - sends file in chunks to upload session location (flip resumable upload protocol),
+// missing parts are asked from upload location so upload can be resumed after interruption
+type chunkUploader struct {
+	httpClient *http.Client
+	retries    int
+	retryDelay time.Duration
+}
+
+// Creates new chunk uploader, every part and every upload round is repeated up to retries times
+func newChunkUploader(httpClient *http.Client, retries int, retryDelay time.Duration) *chunkUploader {
+
+	uploader := new(chunkUploader)
+	uploader.httpClient = httpClient
+	uploader.retries = retries
+	uploader.retryDelay = retryDelay
+
+	if uploader.httpClient == nil {
+		uploader.httpClient = http.DefaultClient
+	}
+
+	return uploader
+}
+
+// Upload all missing parts of file to session location, onPart is called after each uploaded part.
+// Returns id of created media (empty if all parts were uploaded before).
+func (uploader *chunkUploader) upload(ctx context.Context, session flip.UploadSession, file io.ReaderAt, size int64,
+	onPart func(part int, partSize int64)) (string, error) {
+
+	if session.Parts <= 0 || session.PartSize <= 0 {
+
+		return "", errors.New("Invalid upload session: no parts to upload")
+	}
+
+	mediaId := ""
+	var lastErr error
+
+	// every round uploads parts which are reported as missing, the last round only checks status
+	for round := 0; round <= uploader.retries+1; round++ {
+
+		if lastErr != nil {
+			if err := sleepContext(ctx, uploader.retryDelay); err != nil {
+				return mediaId, err
+			}
+		}
+
+		status, err := uploader.status(ctx, session.Location)
+		if err != nil {
+
+			lastErr = err
+			continue
+		}
+
+		if len(status.MissingParts) == 0 {
+
+			return mediaId, nil
+		}
+
+		if round > uploader.retries {
+
+			lastErr = fmt.Errorf("missing parts %v", status.MissingParts)
+			break
+		}
+
+		id, err := uploader.uploadParts(ctx, session, file, size, status.MissingParts, onPart)
+		if id != "" {
+			mediaId = id
+		}
+
+		lastErr = err
+		if ctx.Err() != nil {
+
+			return mediaId, ctx.Err()
+		}
+	}
+
+	if lastErr == nil {
+
+		lastErr = errors.New("some parts were not uploaded")
+	}
+
+	return mediaId, errors.New("Upload not finished: " + lastErr.Error())
+}
+
+// Upload given parts using session max connections workers
+func (uploader *chunkUploader) uploadParts(ctx context.Context, session flip.UploadSession, file io.ReaderAt,
+	size int64, parts []int, onPart func(part int, partSize int64)) (string, error) {
+
+	workers := int(session.MaxConnections)
+	if workers <= 0 {
+		workers = 1
+	}
+	if workers > len(parts) {
+		workers = len(parts)
+	}
+
+	partsCh := make(chan int)
+	var waitGroup sync.WaitGroup
+	var mutex sync.Mutex
+	mediaId := ""
+	var firstErr error
+
+	for i := 0; i < workers; i++ {
+
+		waitGroup.Add(1)
+		go func() {
+
+			defer waitGroup.Done()
+
+			for part := range partsCh {
+
+				offset := int64(part) * int64(session.PartSize)
+				partSize := int64(session.PartSize)
+				if offset+partSize > size {
+					partSize = size - offset
+				}
+
+				id, err := uploader.uploadPart(ctx, session.Location, file, part, offset, partSize)
+
+				mutex.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				if id != "" {
+					mediaId = id
+				}
+				mutex.Unlock()
+
+				if err == nil && onPart != nil {
+					onPart(part, partSize)
+				}
+			}
+		}()
+	}
+
+	for _, part := range parts {
+
+		if part < 0 || part >= int(session.Parts) {
+			continue
+		}
+
+		select {
+		case partsCh <- part:
+		case <-ctx.Done():
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	close(partsCh)
+	waitGroup.Wait()
+
+	if ctx.Err() != nil {
+
+		return mediaId, ctx.Err()
+	}
+
+	return mediaId, firstErr
+}
+
+// Send single part, part is repeated up to retries times
+func (uploader *chunkUploader) uploadPart(ctx context.Context, location string, file io.ReaderAt, part int,
+	offset int64, partSize int64) (string, error) {
+
+	var err error
+
+	for attempt := 0; attempt <= uploader.retries; attempt++ {
+
+		if attempt > 0 {
+			if sleepErr := sleepContext(ctx, uploader.retryDelay); sleepErr != nil {
+				return "", sleepErr
+			}
+		}
+
+		var req *http.Request
+		req, err = http.NewRequest("PUT", location, io.NewSectionReader(file, offset, partSize))
+		if err != nil {
+			return "", err
+		}
+
+		req = req.WithContext(ctx)
+		req.ContentLength = partSize
+		req.Header.Set("Content-Type", "application/octet-stream")
+		req.Header.Set("X-Part", strconv.Itoa(part))
+
+		var resp *http.Response
+		resp, err = uploader.httpClient.Do(req)
+		if err != nil {
+			continue
+		}
+
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+
+		if resp.StatusCode >= 300 {
+
+			err = fmt.Errorf("part %d: status: %v, body: %s", part, resp.Status, body)
+			continue
+		}
+
+		var mediaIdResponse struct {
+			Id string `json:"id"`
+		}
+		json.Unmarshal(body, &mediaIdResponse)
+
+		return mediaIdResponse.Id, nil
+	}
+
+	return "", err
+}
+
+// Get parts which are still missing in upload session
+func (uploader *chunkUploader) status(ctx context.Context, location string) (uploadStatus, error) {
+
+	status := uploadStatus{}
+
+	req, err := http.NewRequest("GET", location, nil)
+	if err != nil {
+		return status, err
+	}
+
+	resp, err := uploader.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return status, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	if resp.StatusCode >= 300 {
+
+		return status, fmt.Errorf("upload status: %v, body: %s", resp.Status, body)
+	}
+
+	err = json.Unmarshal(body, &status)
+
+	return status, err
+}
+
+// Sleep given time or until context is done
+func sleepContext(ctx context.Context, duration time.Duration) error {
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Upload files using concurrency workers, every file is uploaded in its own upload session. State of uploads
+// is stored in user cache directory so next upload of the same files resumes interrupted uploads, finished
+// ones are skipped when resume is set.
+func (client *FlipClient) uploadFiles(ctx context.Context, factoryId string, paths []string,
+	uploadBody flip.VideoUploadBody, concurrency int, resume bool) []uploadResult {
+
+	results := make([]uploadResult, len(paths))
+	trackers := make([]*progress.Tracker, len(paths))
+	state := openUploadState()
+
+	for idx, path := range paths {
+
+		trackers[idx] = &progress.Tracker{Message: filepath.Base(path), Units: progress.UnitsBytes}
+
+		if fileInfo, err := os.Stat(path); err == nil {
+			trackers[idx].Total = fileInfo.Size()
+		}
+	}
+
+	rendered := make(chan bool)
+	var writer *progress.Progress
+
+	if uploadProgressOutput != nil {
+
+		writer = new(progress.Progress)
+		writer.SetOutputWriter(uploadProgressOutput)
+		writer.SetAutoStop(true)
+		writer.SetTrackerLength(30)
+		writer.SetMessageWidth(30)
+		writer.ShowTime(true)
+		writer.AppendTrackers(trackers)
+
+		go func() {
+
+			writer.Render()
+			close(rendered)
+		}()
+	}
+
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	indexes := make(chan int)
+	var waitGroup sync.WaitGroup
+
+	for i := 0; i < concurrency; i++ {
+
+		waitGroup.Add(1)
+		go func() {
+
+			defer waitGroup.Done()
+
+			for idx := range indexes {
+
+				results[idx] = client.uploadFile(ctx, factoryId, paths[idx], uploadBody, state, resume,
+					trackers[idx])
+
+				if results[idx].err != nil && ctx.Err() != nil {
+
+					results[idx].setError(errors.New("Upload interrupted, run the same command again to resume it"))
+					results[idx].Status = "interrupted"
+				}
+
+				// failed uploads are finished too, so progress rendering can stop
+				trackers[idx].MarkAsDone()
+			}
+		}()
+	}
+
+	for idx := range paths {
+		indexes <- idx
+	}
+
+	close(indexes)
+	waitGroup.Wait()
+
+	if writer != nil {
+		<-rendered
+	}
+
+	return results
+}
+
+// Upload single file, upload session saved in state is reused if upload location still knows it. Finished
+// upload is skipped only when resume is set, otherwise file is uploaded again.
+func (client *FlipClient) uploadFile(ctx context.Context, factoryId string, path string,
+	uploadBody flip.VideoUploadBody, state *uploadState, resume bool, tracker *progress.Tracker) uploadResult {
+
+	result := uploadResult{File: path}
+	name := filepath.Base(path)
+
+	// state key, relative path is used when absolute one cannot be found
+	key := path
+	if absPath, err := filepath.Abs(path); err == nil {
+		key = absPath
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+
+		return result.setError(err)
+	}
+	defer file.Close()
+
+	fileInfo, err := file.Stat()
+	if err != nil {
+
+		return result.setError(err)
+	}
+
+	entry := state.entry(key, factoryId, fileInfo)
+	if entry.Finished && resume {
+
+		tracker.SetValue(fileInfo.Size())
+		result.Status = "skipped"
+		result.VideoId = entry.VideoId
+		return result
+	}
+
+	if entry.Finished {
+		entry.Session = flip.UploadSession{}
+	}
+
+	uploader := newChunkUploader(client.config.HTTPClient, uploadRetries, uploadRetryDelay)
+
+	if entry.Session.Id != "" {
+		if _, err := uploader.status(ctx, entry.Session.Location); err != nil {
+
+			entry.Session = flip.UploadSession{}
+		}
+	}
+
+	if entry.Session.Id == "" {
+
+		uploadBody.FileName = name
+		uploadBody.FileSize = fileInfo.Size()
+		uploadBody.MultiChunk = true
+
+		session, resp, err := client.client.FlipApi.UploadVideo(ctx, factoryId, uploadBody)
+		if err != nil {
+
+			return result.setError(newApiError("UploadVideo", resp, err))
+		}
+
+		state.startSession(key, session)
+
+		entry.Session = session
+		entry.CompletedOffsets = nil
+	}
+
+	tracker.SetValue(entry.uploadedBytes())
+
+	videoId, err := uploader.upload(ctx, entry.Session, file, fileInfo.Size(), func(part int, partSize int64) {
+
+		tracker.SetValue(state.completePart(key, part))
+	})
+
+	if err != nil {
+
+		return result.setError(err)
+	}
+
+	state.finish(key, videoId)
+
+	tracker.SetValue(fileInfo.Size())
+	result.Status = "uploaded"
+	result.VideoId = videoId
+
+	return result
+}
+
+// Set upload error, upload is marked as failed
+func (result *uploadResult) setError(err error) uploadResult {
+
+	result.Status = "failed"
+	result.Error = err.Error()
+	result.err = err
+
+	return *result
+}
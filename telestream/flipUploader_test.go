@@ -0,0 +1,387 @@
+package telestream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Telestream/telestream-cloud-go-sdk/flip"
+	"github.com/stretchr/testify/assert"
+
+	"tcs-cli/cli"
+)
+
+// uploadServer - stand-in of flip upload endpoints: upload session creation, upload status, parts upload
+// and video description
+type uploadServer struct {
+	server   *httptest.Server
+	mutex    sync.Mutex
+	partSize int
+	sessions map[string]*uploadServerSession
+	failPart map[int]int
+}
+
+type uploadServerSession struct {
+	name  string
+	parts map[int][]byte
+	count int
+}
+
+func newUploadServer(partSize int) *uploadServer {
+
+	server := &uploadServer{partSize: partSize, sessions: map[string]*uploadServerSession{}, failPart: map[int]int{}}
+	server.server = httptest.NewServer(http.HandlerFunc(server.handle))
+
+	return server
+}
+
+func (server *uploadServer) handle(w http.ResponseWriter, r *http.Request) {
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	switch {
+
+	case r.Method == "POST" && r.URL.Path == "/videos/upload.json":
+		body := flip.VideoUploadBody{}
+		json.NewDecoder(r.Body).Decode(&body)
+
+		id := "session" + strconv.Itoa(len(server.sessions))
+		parts := int((body.FileSize + int64(server.partSize) - 1) / int64(server.partSize))
+		server.sessions[id] = &uploadServerSession{name: body.FileName, parts: map[int][]byte{}, count: parts}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": id, "location": server.server.URL + "/upload/" + id,
+			"parts": strconv.Itoa(parts), "part_size": strconv.Itoa(server.partSize), "max_connections": "2"})
+
+	case r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/upload/"):
+		session := server.sessions[strings.TrimPrefix(r.URL.Path, "/upload/")]
+		missing := []int{}
+		for part := 0; part < session.count; part++ {
+			if _, ok := session.parts[part]; !ok {
+				missing = append(missing, part)
+			}
+		}
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"missing_parts": missing})
+
+	case r.Method == "PUT" && strings.HasPrefix(r.URL.Path, "/upload/"):
+		id := strings.TrimPrefix(r.URL.Path, "/upload/")
+		session := server.sessions[id]
+		part, _ := strconv.Atoi(r.Header.Get("X-Part"))
+
+		if server.failPart[part] > 0 {
+
+			server.failPart[part]--
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+
+		session.parts[part], _ = ioutil.ReadAll(r.Body)
+
+		if len(session.parts) == session.count {
+			json.NewEncoder(w).Encode(map[string]string{"id": "video_" + id})
+		}
+
+	case r.Method == "GET" && strings.HasPrefix(r.URL.Path, "/videos/video_"):
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/videos/video_"), ".json")
+		json.NewEncoder(w).Encode(flip.Video{Id: "video_" + id, OriginalFilename: server.sessions[id].name,
+			Status: "processing"})
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// Get content of uploaded file joined from received parts
+func (server *uploadServer) content(id string) []byte {
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	content := []byte{}
+	session := server.sessions[id]
+
+	for part := 0; part < session.count; part++ {
+		content = append(content, session.parts[part]...)
+	}
+
+	return content
+}
+
+func writeTestFile(t *testing.T, dir string, name string, size int) (string, []byte) {
+
+	content := bytes.Repeat([]byte("0123456789"), size/10+1)[:size]
+	path := filepath.Join(dir, name)
+
+	assert.Nil(t, ioutil.WriteFile(path, content, 0644))
+
+	return path, content
+}
+
+func Test_chunkUploader_upload(t *testing.T) {
+
+	server := newUploadServer(4)
+	defer server.server.Close()
+
+	content := []byte("0123456789")
+	session := flip.UploadSession{Id: "session0", Location: server.server.URL + "/upload/session0", Parts: 3,
+		PartSize: 4, MaxConnections: 2}
+	server.sessions["session0"] = &uploadServerSession{name: "file", parts: map[int][]byte{}, count: 3}
+
+	// part 1 fails more times than single part retries - it is resumed in next round
+	server.failPart[1] = 3
+
+	uploaded := 0
+	uploader := newChunkUploader(nil, 2, time.Millisecond)
+	mediaId, err := uploader.upload(context.Background(), session, bytes.NewReader(content), int64(len(content)),
+		func(part int, partSize int64) { uploaded++ })
+
+	assert.Nil(t, err)
+	assert.Equal(t, "video_session0", mediaId)
+	assert.Equal(t, 3, uploaded)
+	assert.Equal(t, content, server.content("session0"))
+
+	// nothing is missing - nothing is uploaded again
+	mediaId, err = uploader.upload(context.Background(), session, bytes.NewReader(content), int64(len(content)), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "", mediaId)
+
+	// part fails all the time
+	server.sessions["session1"] = &uploadServerSession{name: "file", parts: map[int][]byte{}, count: 3}
+	server.failPart[2] = 100
+	session.Location = server.server.URL + "/upload/session1"
+
+	_, err = uploader.upload(context.Background(), session, bytes.NewReader(content), int64(len(content)), nil)
+	assert.NotNil(t, err)
+}
+
+func uploadVideoArgs(factoryId string, file string, dir string, concurrency string) cli.FlagMap {
+
+	profiles := "h264"
+
+	return cli.FlagMap{"factory_id": {Value: &factoryId, IsRequired: true}, "file": {Value: &file},
+		"dir": {Value: &dir}, "concurrency": {Value: &concurrency}, "profiles": {Value: &profiles}}
+}
+
+// Keep upload state in given file instead of user cache directory, returned function restores state path and
+// warnings output
+func useUploadState(path string, warnings io.Writer) func() {
+
+	statePath, stateWarnings := uploadStatePath, uploadStateWarnings
+
+	uploadStatePath = func() (string, error) { return path, nil }
+	uploadStateWarnings = warnings
+
+	return func() { uploadStatePath, uploadStateWarnings = statePath, stateWarnings }
+}
+
+func Test_FlipClient_UploadVideo(t *testing.T) {
+
+	server := newUploadServer(1024)
+	defer server.server.Close()
+
+	dir, err := ioutil.TempDir("", "tcs-upload")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	warnings := new(bytes.Buffer)
+	defer useUploadState(filepath.Join(dir, "state", "upload-state.json"), warnings)()
+
+	path, content := writeTestFile(t, dir, "video.mp4", 3000)
+
+	buffer := new(bytes.Buffer)
+	client := NewFlipClient("key", "", "", NewServiceToJson(buffer, ""))
+	client.config.BasePath = server.server.URL
+
+	assert.Nil(t, client.UploadVideo(uploadVideoArgs("factory", path, "", "")))
+
+	assert.JSONEq(t, `{"id": "video_session0", "original_filename": "video.mp4", "status": "processing"}`,
+		buffer.String())
+	assert.Equal(t, content, server.content("session0"))
+
+	stateInfo, err := os.Stat(filepath.Join(dir, "state", "upload-state.json"))
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), stateInfo.Mode().Perm())
+
+	// finished upload is uploaded again unless it is resumed
+	buffer.Reset()
+	assert.Nil(t, client.UploadVideo(uploadVideoArgs("factory", path, "", "")))
+
+	assert.JSONEq(t, `{"id": "video_session1", "original_filename": "video.mp4", "status": "processing"}`,
+		buffer.String())
+	assert.Equal(t, 2, len(server.sessions))
+
+	buffer.Reset()
+	args := uploadVideoArgs("factory", path, "", "")
+	resume := "true"
+	args["resume"] = cli.FlagProperties{Value: &resume}
+	assert.Nil(t, client.UploadVideo(args))
+
+	assert.JSONEq(t, `{"id": "video_session1", "original_filename": "video.mp4", "status": "processing"}`,
+		buffer.String())
+	assert.Equal(t, 2, len(server.sessions))
+	assert.Equal(t, "", warnings.String())
+
+	err = client.UploadVideo(uploadVideoArgs("factory", filepath.Join(dir, "missing.mp4"), "", ""))
+	assert.Equal(t, ExitFailure, ExitCode(err))
+
+	err = client.UploadVideo(uploadVideoArgs("factory", path, dir, ""))
+	assert.Equal(t, ExitUsage, ExitCode(err))
+
+	err = client.UploadVideo(uploadVideoArgs("factory", path, "", "zero"))
+	assert.Equal(t, ExitUsage, ExitCode(err))
+}
+
+func Test_FlipClient_UploadVideo_stateNotSaved(t *testing.T) {
+
+	uploadProgressOutput = nil
+
+	server := newUploadServer(1024)
+	defer server.server.Close()
+
+	dir, err := ioutil.TempDir("", "tcs-upload")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	// state directory cannot be created - its parent is a file
+	blocker, _ := writeTestFile(t, dir, "blocker", 10)
+	warnings := new(bytes.Buffer)
+	defer useUploadState(filepath.Join(blocker, "upload-state.json"), warnings)()
+
+	path, content := writeTestFile(t, dir, "video.mp4", 3000)
+
+	buffer := new(bytes.Buffer)
+	client := NewFlipClient("key", "", "", NewServiceToJson(buffer, ""))
+	client.config.BasePath = server.server.URL
+
+	assert.Nil(t, client.UploadVideo(uploadVideoArgs("factory", path, "", "")))
+
+	assert.JSONEq(t, `{"id": "video_session0", "original_filename": "video.mp4", "status": "processing"}`,
+		buffer.String())
+	assert.Equal(t, content, server.content("session0"))
+	assert.Equal(t, 1, strings.Count(warnings.String(), "Warning: Upload state: "))
+}
+
+func Test_FlipClient_UploadVideo_dir(t *testing.T) {
+
+	uploadProgressOutput = nil
+
+	server := newUploadServer(1000)
+	defer server.server.Close()
+
+	dir, err := ioutil.TempDir("", "tcs-upload")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	defer useUploadState(filepath.Join(dir, "state", "upload-state.json"), new(bytes.Buffer))()
+
+	_, finishedContent := writeTestFile(t, dir, "a_finished.mp4", 1500)
+	_, partialContent := writeTestFile(t, dir, "b_partial.mp4", 3500)
+	_, newContent := writeTestFile(t, dir, "c_new.mp4", 2000)
+	writeTestFile(t, dir, ".hidden", 10)
+
+	finishedInfo, _ := os.Stat(filepath.Join(dir, "a_finished.mp4"))
+	partialInfo, _ := os.Stat(filepath.Join(dir, "b_partial.mp4"))
+
+	// state left by interrupted run: first file finished, second one has first two parts uploaded
+	server.sessions["session0"] = &uploadServerSession{name: "a_finished.mp4",
+		parts: map[int][]byte{0: finishedContent[:1000], 1: finishedContent[1000:]}, count: 2}
+	server.sessions["session1"] = &uploadServerSession{name: "b_partial.mp4",
+		parts: map[int][]byte{0: partialContent[:1000], 1: partialContent[1000:2000]}, count: 4}
+
+	finishedKey := filepath.Join(dir, "a_finished.mp4")
+	partialKey := filepath.Join(dir, "b_partial.mp4")
+
+	state := openUploadState()
+
+	state.entry(finishedKey, "factory", finishedInfo)
+	state.startSession(finishedKey, flip.UploadSession{Id: "session0",
+		Location: server.server.URL + "/upload/session0", Parts: 2, PartSize: 1000, MaxConnections: 2})
+	state.finish(finishedKey, "video_session0")
+
+	state.entry(partialKey, "factory", partialInfo)
+	state.startSession(partialKey, flip.UploadSession{Id: "session1",
+		Location: server.server.URL + "/upload/session1", Parts: 4, PartSize: 1000, MaxConnections: 2})
+	assert.Equal(t, int64(1000), state.completePart(partialKey, 0))
+	assert.Equal(t, int64(2000), state.completePart(partialKey, 1))
+
+	buffer := new(bytes.Buffer)
+	client := NewFlipClient("key", "", "", NewServiceToJson(buffer, ""))
+	client.config.BasePath = server.server.URL
+
+	assert.Nil(t, client.UploadVideo(uploadVideoArgs("factory", "", dir, "3")))
+
+	assert.JSONEq(t, `[
+		{"file": "`+filepath.Join(dir, "a_finished.mp4")+`", "status": "skipped", "video_id": "video_session0"},
+		{"file": "`+filepath.Join(dir, "b_partial.mp4")+`", "status": "uploaded", "video_id": "video_session1"},
+		{"file": "`+filepath.Join(dir, "c_new.mp4")+`", "status": "uploaded", "video_id": "video_session2"}]`,
+		buffer.String())
+
+	assert.Equal(t, 3, len(server.sessions))
+	assert.Equal(t, partialContent, server.content("session1"))
+	assert.Equal(t, newContent, server.content("session2"))
+
+	state = openUploadState()
+	assert.Equal(t, []int64{0, 1000, 2000, 3000}, state.entries[partialKey].CompletedOffsets)
+	assert.True(t, state.entries[filepath.Join(dir, "c_new.mp4")].Finished)
+
+	// changed file is uploaded again in new session
+	writeTestFile(t, dir, "c_new.mp4", 2500)
+	buffer.Reset()
+	assert.Nil(t, client.UploadVideo(uploadVideoArgs("factory", "", dir, "")))
+
+	assert.Contains(t, buffer.String(), `"video_id":"video_session3"`)
+	assert.Equal(t, 4, len(server.sessions))
+}
+
+func Test_uploadState_entry(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "tcs-upload")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path, _ := writeTestFile(t, dir, "video.mp4", 2500)
+	fileInfo, _ := os.Stat(path)
+
+	statePath := filepath.Join(dir, "upload-state.json")
+	warnings := new(bytes.Buffer)
+	defer useUploadState(statePath, warnings)()
+
+	state := openUploadState()
+
+	state.entry(path, "factory", fileInfo)
+	state.startSession(path, flip.UploadSession{Id: "session", PartSize: 1000})
+	assert.Equal(t, int64(500), state.completePart(path, 2))
+	assert.Equal(t, int64(1500), state.completePart(path, 0))
+	assert.Equal(t, int64(1500), state.completePart(path, 2))
+
+	loaded, err := loadUploadState(statePath)
+	assert.Nil(t, err)
+
+	entry := loaded.entry(path, "factory", fileInfo)
+	assert.Equal(t, "session", entry.Session.Id)
+	assert.Equal(t, []int64{0, 2000}, entry.CompletedOffsets)
+
+	// other factory starts upload from the beginning
+	entry = loaded.entry(path, "other", fileInfo)
+	assert.Equal(t, "", entry.Session.Id)
+
+	// broken state is replaced by empty one with warning
+	ioutil.WriteFile(statePath, []byte("{"), 0600)
+	_, err = loadUploadState(statePath)
+	assert.NotNil(t, err)
+
+	assert.Equal(t, 0, len(openUploadState().entries))
+	assert.Contains(t, warnings.String(), "Warning: Upload state: ")
+}
@@ -0,0 +1,94 @@
+package telestream
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Telestream/telestream-cloud-go-sdk/flip"
+	"github.com/jedib0t/go-pretty/table"
+)
+
+// default and minimal delay between refreshes of watched encodings, shorter intervals only flood API
+var watchInterval = 5 * time.Second
+var watchMinInterval = time.Second
+
+// watch frames are written to stdout, previous frame is overwritten on terminal only
+var watchOutput io.Writer = os.Stdout
+var watchRedraw = isTerminal(os.Stdout)
+
+// encodingsWatcher - draws table of encodings progress, every frame replaces previous one when redraw is set
+type encodingsWatcher struct {
+	writer    io.Writer
+	redraw    bool
+	lastLines int
+}
+
+// Creates new encodings watcher writing frames to given writer
+func newEncodingsWatcher(writer io.Writer, redraw bool) *encodingsWatcher {
+
+	watcher := new(encodingsWatcher)
+	watcher.writer = writer
+	watcher.redraw = redraw
+
+	return watcher
+}
+
+// Draw encodings table, cursor is moved to the beginning of previous frame which is cleared before drawing
+func (watcher *encodingsWatcher) draw(encodings []flip.Encoding, now time.Time) {
+
+	tableWriter := table.NewWriter()
+	tableWriter.SetStyle(table.StyleLight)
+	tableWriter.AppendHeader(table.Row{"ID", "VIDEO_ID", "PROFILE_NAME", "STATUS", "PROGRESS", "ETA"})
+
+	for _, encoding := range encodings {
+
+		tableWriter.AppendRow(table.Row{encoding.Id, encoding.VideoId, encoding.ProfileName, encoding.Status,
+			fmt.Sprintf("%d%%", encoding.EncodingProgress), encodingEta(encoding, now)})
+	}
+
+	frame := "Encodings at " + now.Format("15:04:05") + "\n" + tableWriter.Render() + "\n"
+
+	if watcher.redraw && watcher.lastLines > 0 {
+
+		frame = fmt.Sprintf("\033[%dA\033[J", watcher.lastLines) + frame
+	}
+
+	io.WriteString(watcher.writer, frame)
+	watcher.lastLines = strings.Count(frame, "\n")
+}
+
+// Estimate time left to finish encoding on the basis of its progress and encoding start time
+func encodingEta(encoding flip.Encoding, now time.Time) string {
+
+	if _, finished := terminalStatuses[strings.ToLower(encoding.Status)]; finished {
+
+		return ""
+	}
+
+	startedAt, err := time.Parse(time.RFC3339, encoding.StartedEncodingAt)
+	if err != nil || encoding.EncodingProgress <= 0 || encoding.EncodingProgress >= 100 || now.Before(startedAt) {
+
+		return "-"
+	}
+
+	elapsed := now.Sub(startedAt)
+	left := elapsed * time.Duration(100-encoding.EncodingProgress) / time.Duration(encoding.EncodingProgress)
+
+	return left.Round(time.Second).String()
+}
+
+// Check if all encodings reached terminal status
+func allEncodingsFinished(encodings []flip.Encoding) bool {
+
+	for _, encoding := range encodings {
+		if _, finished := terminalStatuses[strings.ToLower(encoding.Status)]; !finished {
+
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,141 @@
+package telestream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Telestream/telestream-cloud-go-sdk/flip"
+	"github.com/stretchr/testify/assert"
+
+	"tcs-cli/cli"
+)
+
+func Test_encodingEta(t *testing.T) {
+
+	now := time.Date(2019, 6, 10, 12, 0, 0, 0, time.UTC)
+
+	var testVector = []struct {
+		name     string
+		encoding flip.Encoding
+		eta      string
+	}{
+		{"quarter done", flip.Encoding{Status: "processing", EncodingProgress: 25,
+			StartedEncodingAt: "2019-06-10T11:59:00Z"}, "3m0s"},
+		{"half done", flip.Encoding{Status: "processing", EncodingProgress: 50,
+			StartedEncodingAt: "2019-06-10T11:59:30+00:00"}, "30s"},
+		{"not started", flip.Encoding{Status: "pending"}, "-"},
+		{"no progress", flip.Encoding{Status: "processing", StartedEncodingAt: "2019-06-10T11:59:00Z"}, "-"},
+		{"finished", flip.Encoding{Status: "success", EncodingProgress: 100}, ""},
+	}
+
+	for _, testEl := range testVector {
+
+		t.Run(testEl.name, func(t *testing.T) {
+
+			assert.Equal(t, testEl.eta, encodingEta(testEl.encoding, now))
+		})
+	}
+}
+
+func Test_encodingsWatcher_draw(t *testing.T) {
+
+	now := time.Date(2019, 6, 10, 12, 0, 0, 0, time.UTC)
+	encodings := []flip.Encoding{{Id: "1", VideoId: "v", ProfileName: "h264", Status: "processing",
+		EncodingProgress: 50, StartedEncodingAt: "2019-06-10T11:59:00Z"}}
+
+	buffer := new(bytes.Buffer)
+	watcher := newEncodingsWatcher(buffer, true)
+
+	watcher.draw(encodings, now)
+	frame := buffer.String()
+
+	assert.True(t, strings.HasPrefix(frame, "Encodings at 12:00:00\n"))
+	assert.Contains(t, frame, "│ 1  │ v        │ h264         │ processing │ 50%      │ 1m0s │")
+
+	// next frame clears previous one
+	buffer.Reset()
+	watcher.draw(encodings, now)
+	assert.True(t, strings.HasPrefix(buffer.String(), "\033[6A\033[J"))
+
+	buffer.Reset()
+	watcher = newEncodingsWatcher(buffer, false)
+	watcher.draw(encodings, now)
+	watcher.draw(encodings, now)
+	assert.Equal(t, frame+frame, buffer.String())
+}
+
+func Test_FlipClient_WatchEncodings(t *testing.T) {
+
+	frames := [][]flip.Encoding{
+		{{Id: "1", Status: "processing", EncodingProgress: 10}, {Id: "2", Status: "pending"}},
+		{{Id: "1", Status: "success", EncodingProgress: 100}, {Id: "2", Status: "processing", EncodingProgress: 40}},
+		{{Id: "1", Status: "success", EncodingProgress: 100}, {Id: "2", Status: "fail", EncodingProgress: 40}},
+	}
+	polls := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		assert.Equal(t, "/encodings.json", r.URL.Path)
+		assert.Equal(t, "video", r.URL.Query().Get("video_id"))
+
+		encodings := frames[polls]
+		polls++
+
+		json.NewEncoder(w).Encode(flip.PaginatedEncodingsCollection{Encodings: encodings,
+			Total: int32(len(encodings))})
+	}))
+	defer server.Close()
+
+	defer func(output io.Writer, redraw bool, minInterval time.Duration) {
+		watchOutput, watchRedraw, watchMinInterval = output, redraw, minInterval
+	}(watchOutput, watchRedraw, watchMinInterval)
+
+	buffer := new(bytes.Buffer)
+	watchOutput = buffer
+	watchRedraw = false
+	watchMinInterval = time.Millisecond
+
+	client := NewFlipClient("key", "", "", NewServiceToJson(buffer, ""))
+	client.config.BasePath = server.URL
+
+	factoryId := "factory"
+	videoId := "video"
+	interval := "1ms"
+	err := client.WatchEncodings(cli.FlagMap{"factory_id": {Value: &factoryId, IsRequired: true},
+		"video_id": {Value: &videoId}, "interval": {Value: &interval}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, polls)
+	assert.Equal(t, 3, strings.Count(buffer.String(), "Encodings at"))
+	assert.Contains(t, buffer.String(), "fail")
+
+	buffer.Reset()
+	for _, interval = range []string{"often", "100us"} {
+
+		err = client.WatchEncodings(cli.FlagMap{"factory_id": {Value: &factoryId, IsRequired: true},
+			"video_id": {Value: &videoId}, "interval": {Value: &interval}})
+
+		assert.Equal(t, ExitUsage, ExitCode(err))
+	}
+	assert.Equal(t, 3, polls)
+
+	// canceled watch does not wait for next refresh
+	polls = 0
+	interval = "1h"
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	client.ctx = ctx
+
+	err = client.WatchEncodings(cli.FlagMap{"factory_id": {Value: &factoryId, IsRequired: true},
+		"video_id": {Value: &videoId}, "interval": {Value: &interval}})
+
+	assert.Equal(t, 1, polls)
+	assert.Equal(t, context.DeadlineExceeded, err.(*CommandError).Err)
+}
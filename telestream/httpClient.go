@@ -0,0 +1,146 @@
+package telestream
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"tcs-cli/cli"
+)
+
+// default time of waiting for API response, so hanging server does not block tcs forever
+const defaultHttpTimeout = 60 * time.Second
+
+// Creates http client shared by flip and tts API clients: request timeout, proxy (environment proxy by
+// default), private CA certificates, client certificate, disabled server certificate verification, retries
+// of failed requests and rate limit are taken from profile settings. Requests are logged when http log is on.
+func NewHttpClient(settings ProfileSettings) (*http.Client, error) {
+
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+
+	if settings.Proxy != "" {
+
+		proxyUrl, err := url.Parse(settings.Proxy)
+		if err != nil || proxyUrl.Scheme == "" || proxyUrl.Host == "" {
+
+			return nil, cli.NewUsageError("Invalid proxy: " + settings.Proxy)
+		}
+
+		transport.Proxy = http.ProxyURL(proxyUrl)
+	}
+
+	tlsConfig, err := newTlsConfig(settings)
+	if err != nil {
+
+		return nil, err
+	}
+
+	transport.TLSClientConfig = tlsConfig
+
+	retries, err := parseRetries(settings.Retries)
+	if err != nil {
+
+		return nil, err
+	}
+
+	retryMaxWait := defaultRetryMaxWait
+	if settings.RetryMaxWait != "" {
+
+		if retryMaxWait, err = parseDuration(settings.RetryMaxWait); err != nil {
+
+			return nil, cli.NewUsageError("Invalid retry_max_wait: " + settings.RetryMaxWait)
+		}
+	}
+
+	limiter, err := parseRateLimit(settings.Rps, settings.ApiKey)
+	if err != nil {
+
+		return nil, err
+	}
+
+	// every retry takes its own token of rate limiter and it is logged as separate request
+	var next http.RoundTripper = &logTransport{next: transport}
+	if limiter != nil {
+		next = newRateLimitTransport(next, limiter)
+	}
+
+	httpClient := &http.Client{Transport: newRetryTransport(next, retries, retryMaxWait)}
+
+	// by default only waiting for response is limited, so sending of long request body (e.g. upload part) is
+	// not interrupted. Timeout limits the whole request with its retries, 0 turns timeouts off.
+	transport.ResponseHeaderTimeout = defaultHttpTimeout
+
+	if settings.Timeout != "" {
+
+		timeout, err := parseDuration(settings.Timeout)
+		if err != nil {
+
+			return nil, cli.NewUsageError("Invalid timeout: " + settings.Timeout)
+		}
+
+		httpClient.Timeout = timeout
+		transport.ResponseHeaderTimeout = timeout
+	}
+
+	return httpClient, nil
+}
+
+// Creates TLS configuration with system and given CA certificates and client certificate
+func newTlsConfig(settings ProfileSettings) (*tls.Config, error) {
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: settings.InsecureSkipVerify}
+
+	if settings.CaCert != "" {
+
+		pem, err := ioutil.ReadFile(settings.CaCert)
+		if err != nil {
+
+			return nil, errors.New("Cannot read CA certificate: " + err.Error())
+		}
+
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil || rootCAs == nil {
+			rootCAs = x509.NewCertPool()
+		}
+
+		if !rootCAs.AppendCertsFromPEM(pem) {
+
+			return nil, errors.New("No PEM certificate found in " + settings.CaCert)
+		}
+
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	if settings.ClientCert != "" || settings.ClientKey != "" {
+
+		if settings.ClientCert == "" || settings.ClientKey == "" {
+
+			return nil, cli.NewUsageError("Both client_cert and client_key must be set")
+		}
+
+		certificate, err := tls.LoadX509KeyPair(settings.ClientCert, settings.ClientKey)
+		if err != nil {
+
+			return nil, errors.New("Cannot load client certificate: " + err.Error())
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
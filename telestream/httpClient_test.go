@@ -0,0 +1,128 @@
+package telestream
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewHttpClient_tls(t *testing.T) {
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "tcs-http")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	caCert := filepath.Join(dir, "ca.pem")
+	pemBlock := &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}
+	assert.Nil(t, ioutil.WriteFile(caCert, pem.EncodeToMemory(pemBlock), 0600))
+
+	var testVector = []struct {
+		name      string
+		settings  ProfileSettings
+		succeeded bool
+	}{
+		{"untrusted certificate", ProfileSettings{}, false},
+		{"private CA", ProfileSettings{CaCert: caCert}, true},
+		{"insecure skip verify", ProfileSettings{InsecureSkipVerify: true}, true},
+	}
+
+	for _, testEl := range testVector {
+
+		t.Run(testEl.name, func(t *testing.T) {
+
+			httpClient, err := NewHttpClient(testEl.settings)
+			assert.Nil(t, err)
+
+			resp, err := httpClient.Get(server.URL)
+			assert.Equal(t, testEl.succeeded, err == nil)
+
+			if err == nil {
+				resp.Body.Close()
+			}
+		})
+	}
+}
+
+func Test_NewHttpClient_proxyAndTimeout(t *testing.T) {
+
+	proxied := ""
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		proxied = r.URL.String()
+	}))
+	defer proxy.Close()
+
+	httpClient, err := NewHttpClient(ProfileSettings{Proxy: proxy.URL})
+	assert.Nil(t, err)
+
+	resp, err := httpClient.Get("http://api.example.com/flip/factories.json")
+	assert.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, "http://api.example.com/flip/factories.json", proxied)
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer slow.Close()
+
+	httpClient, err = NewHttpClient(ProfileSettings{Timeout: "50ms"})
+	assert.Nil(t, err)
+	assert.Equal(t, 50*time.Millisecond, httpClient.Timeout)
+
+	_, err = httpClient.Get(slow.URL)
+	assert.Equal(t, ExitTimeout, ExitCode(err))
+
+	var testVector = []struct {
+		timeout         string
+		clientTimeout   time.Duration
+		responseTimeout time.Duration
+	}{
+		{"", 0, defaultHttpTimeout},
+		{"0", 0, 0},
+		{"90", 90 * time.Second, 90 * time.Second},
+	}
+
+	for _, testEl := range testVector {
+
+		httpClient, err = NewHttpClient(ProfileSettings{Timeout: testEl.timeout})
+		assert.Nil(t, err)
+		assert.Equal(t, testEl.clientTimeout, httpClient.Timeout)
+		transport := httpClient.Transport.(*retryTransport).next.(*logTransport).next.(*http.Transport)
+		assert.Equal(t, testEl.responseTimeout, transport.ResponseHeaderTimeout)
+	}
+}
+
+func Test_NewHttpClient_invalidSettings(t *testing.T) {
+
+	var testVector = []struct {
+		name     string
+		settings ProfileSettings
+		code     int
+	}{
+		{"proxy", ProfileSettings{Proxy: "proxy:8080"}, ExitUsage},
+		{"timeout", ProfileSettings{Timeout: "soon"}, ExitUsage},
+		{"client cert without key", ProfileSettings{ClientCert: "cert.pem"}, ExitUsage},
+		{"missing client cert", ProfileSettings{ClientCert: "missing.pem", ClientKey: "missing.key"}, ExitFailure},
+		{"missing CA cert", ProfileSettings{CaCert: "missing.pem"}, ExitFailure},
+	}
+
+	for _, testEl := range testVector {
+
+		t.Run(testEl.name, func(t *testing.T) {
+
+			_, err := NewHttpClient(testEl.settings)
+			assert.Equal(t, testEl.code, ExitCode(err))
+		})
+	}
+}
@@ -0,0 +1,227 @@
+package telestream
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// levels of http log: nothing, request line with status and latency (-v), headers, bodies and retries (-debug)
+const (
+	LogOff = iota
+	LogVerbose
+	LogDebug
+)
+
+// max number of logged bytes of request and response body
+const logBodyLimit = 4096
+
+// headers which hold credentials, their values are masked in log
+var redactedHeaders = map[string]bool{"X-Api-Key": true, "Authorization": true, "Proxy-Authorization": true}
+
+// writer and level of http log, log is written by one request at a time
+var logOutput io.Writer
+var logLevel = LogOff
+var logMutex sync.Mutex
+
+// logTransport - logs requests sent with next transport and their responses when http log is on
+type logTransport struct {
+	next http.RoundTripper
+}
+
+// Set writer and level of http log (e.g. os.Stderr, LogDebug), LogOff or nil writer turns log off
+func SetLogOutput(writer io.Writer, level int) {
+
+	logMutex.Lock()
+	defer logMutex.Unlock()
+
+	logOutput = writer
+	logLevel = level
+
+	if writer == nil {
+		logLevel = LogOff
+	}
+}
+
+// Print debug message (e.g. retry of failed request) when http log level is debug
+func debugf(format string, args ...interface{}) {
+
+	logMutex.Lock()
+	defer logMutex.Unlock()
+
+	if logLevel >= LogDebug {
+		fmt.Fprintf(logOutput, "DEBUG: "+format+"\n", args...)
+	}
+}
+
+// Get current level of http log
+func currentLogLevel() int {
+
+	logMutex.Lock()
+	defer logMutex.Unlock()
+
+	return logLevel
+}
+
+// Write log entry as a whole, so entries of parallel requests (e.g. uploads) are not mixed
+func writeLog(entry *bytes.Buffer) {
+
+	logMutex.Lock()
+	defer logMutex.Unlock()
+
+	if logOutput != nil {
+		logOutput.Write(entry.Bytes())
+	}
+}
+
+func (transport *logTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	level := currentLogLevel()
+	if level == LogOff {
+
+		return transport.next.RoundTrip(req)
+	}
+
+	entry := new(bytes.Buffer)
+	requestBody := ""
+	if level >= LogDebug {
+		requestBody = logRequestBody(req)
+	}
+
+	start := time.Now()
+	resp, err := transport.next.RoundTrip(req)
+	latency := time.Since(start).Round(time.Millisecond)
+
+	if level < LogDebug {
+
+		if err != nil {
+			fmt.Fprintf(entry, "%s %s -> error: %v (%v)\n", req.Method, req.URL, err, latency)
+		} else {
+			fmt.Fprintf(entry, "%s %s -> %s (%v)\n", req.Method, req.URL, resp.Status, latency)
+		}
+
+		writeLog(entry)
+
+		return resp, err
+	}
+
+	fmt.Fprintf(entry, "> %s %s\n", req.Method, req.URL)
+	writeHeaders(entry, "> ", req.Header)
+	writeBody(entry, "> ", requestBody)
+
+	if err != nil {
+
+		fmt.Fprintf(entry, "< error: %v (%v)\n", err, latency)
+		writeLog(entry)
+
+		return resp, err
+	}
+
+	fmt.Fprintf(entry, "< %s (%v)\n", resp.Status, latency)
+	writeHeaders(entry, "< ", resp.Header)
+	writeBody(entry, "< ", logResponseBody(resp))
+	writeLog(entry)
+
+	return resp, err
+}
+
+// Get logged part of request body, body is read from its copy so request is not changed. Body which cannot
+// be copied is not logged.
+func logRequestBody(req *http.Request) string {
+
+	if req.Body == nil || req.Body == http.NoBody {
+
+		return ""
+	}
+
+	if req.GetBody == nil {
+
+		return "(body not logged)"
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+
+		return "(body not logged)"
+	}
+	defer body.Close()
+
+	return readLogBody(body, req.Header.Get("Content-Type"), req.ContentLength)
+}
+
+// Get logged part of response body, read part is put back in front of unread body
+func logResponseBody(resp *http.Response) string {
+
+	if resp.Body == nil || resp.Body == http.NoBody {
+
+		return ""
+	}
+
+	head := new(bytes.Buffer)
+	io.Copy(head, io.LimitReader(resp.Body, logBodyLimit+1))
+
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head.Bytes()), resp.Body), resp.Body}
+
+	return readLogBody(ioutil.NopCloser(head), resp.Header.Get("Content-Type"), resp.ContentLength)
+}
+
+// Read body for log: binary content is replaced by its size, long text is cut to body limit
+func readLogBody(body io.Reader, contentType string, length int64) string {
+
+	if contentType != "" && !strings.Contains(contentType, "json") && !strings.Contains(contentType, "text") &&
+		!strings.Contains(contentType, "xml") && !strings.Contains(contentType, "form") {
+
+		return fmt.Sprintf("(%d bytes of %s)", length, contentType)
+	}
+
+	content, _ := ioutil.ReadAll(io.LimitReader(body, logBodyLimit+1))
+	if len(content) > logBodyLimit {
+
+		return string(content[:logBodyLimit]) + "... (cut)"
+	}
+
+	return string(content)
+}
+
+// Write sorted headers, values of credential headers are masked
+func writeHeaders(entry *bytes.Buffer, prefix string, header http.Header) {
+
+	keys := []string{}
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		for _, value := range header[key] {
+
+			if redactedHeaders[http.CanonicalHeaderKey(key)] {
+				value = maskApiKey(value)
+			}
+
+			fmt.Fprintf(entry, "%s%s: %s\n", prefix, key, value)
+		}
+	}
+}
+
+func writeBody(entry *bytes.Buffer, prefix string, body string) {
+
+	if body == "" {
+
+		return
+	}
+
+	fmt.Fprintln(entry, strings.TrimSpace(prefix))
+	for _, line := range strings.Split(strings.TrimRight(body, "\n"), "\n") {
+		fmt.Fprintln(entry, prefix+line)
+	}
+}
@@ -0,0 +1,99 @@
+package telestream
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_logTransport(t *testing.T) {
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "req-1")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"video"}`))
+	}))
+	defer server.Close()
+
+	// latency differs between runs
+	latency := regexp.MustCompile(`\([0-9.]+m?s\)`)
+
+	var testVector = []struct {
+		name        string
+		level       int
+		contentType string
+		body        string
+		log         string
+	}{
+		{"off", LogOff, "application/json", `{"source_url":"url"}`, ""},
+		{"verbose", LogVerbose, "application/json", `{"source_url":"url"}`,
+			"POST " + server.URL + "/videos.json -> 201 Created (LATENCY)\n"},
+		{"debug", LogDebug, "application/json", `{"source_url":"url"}`,
+			"> POST " + server.URL + "/videos.json\n" +
+				"> Content-Type: application/json\n" +
+				"> X-Api-Key: ******2345\n" +
+				">\n" +
+				"> {\"source_url\":\"url\"}\n" +
+				"< 201 Created (LATENCY)\n" +
+				"< Content-Length: 14\n" +
+				"< Content-Type: application/json\n" +
+				"< Date: DATE\n" +
+				"< X-Request-Id: req-1\n" +
+				"<\n" +
+				"< {\"id\":\"video\"}\n"},
+		{"binary body", LogDebug, "application/octet-stream", "chunk",
+			"> POST " + server.URL + "/videos.json\n" +
+				"> Content-Type: application/octet-stream\n" +
+				"> X-Api-Key: ******2345\n" +
+				">\n" +
+				"> (5 bytes of application/octet-stream)\n"},
+	}
+
+	for _, testEl := range testVector {
+
+		t.Run(testEl.name, func(t *testing.T) {
+
+			log := new(bytes.Buffer)
+			SetLogOutput(log, testEl.level)
+			defer SetLogOutput(nil, LogOff)
+
+			httpClient := &http.Client{Transport: &logTransport{next: http.DefaultTransport}}
+
+			req, err := http.NewRequest("POST", server.URL+"/videos.json", strings.NewReader(testEl.body))
+			assert.Nil(t, err)
+			req.Header.Set("Content-Type", testEl.contentType)
+			req.Header.Set("X-Api-Key", "tcsapi2345")
+
+			resp, err := httpClient.Do(req)
+			assert.Nil(t, err)
+
+			// logged response body is still read by caller
+			body, err := ioutil.ReadAll(resp.Body)
+			assert.Nil(t, err)
+			resp.Body.Close()
+			assert.Equal(t, `{"id":"video"}`, string(body))
+
+			logged := latency.ReplaceAllString(log.String(), "(LATENCY)")
+			logged = regexp.MustCompile(`Date: .*`).ReplaceAllString(logged, "Date: DATE")
+			assert.True(t, strings.HasPrefix(logged, testEl.log), logged)
+			assert.NotContains(t, logged, "tcsapi2345")
+		})
+	}
+}
+
+func Test_readLogBody(t *testing.T) {
+
+	long := strings.Repeat("a", logBodyLimit+10)
+
+	assert.Equal(t, long[:logBodyLimit]+"... (cut)", readLogBody(strings.NewReader(long), "text/plain", -1))
+	assert.Equal(t, "{}", readLogBody(strings.NewReader("{}"), "", 2))
+	assert.Equal(t, "(3 bytes of image/png)", readLogBody(strings.NewReader("png"), "image/png", 3))
+}
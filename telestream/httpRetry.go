@@ -0,0 +1,219 @@
+package telestream
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"tcs-cli/cli"
+)
+
+// default number of retries of failed request and max delay between retries
+const defaultRetries = 3
+const defaultRetryMaxWait = 30 * time.Second
+
+// delay before the first retry, it doubles with every next retry
+var retryMinWait = 500 * time.Millisecond
+
+// statuses of transient API failures
+var retryStatuses = map[int]bool{http.StatusTooManyRequests: true, http.StatusInternalServerError: true,
+	http.StatusBadGateway: true, http.StatusServiceUnavailable: true, http.StatusGatewayTimeout: true}
+
+// key of context value which marks POST request as safe to repeat
+type safeRetryKey struct{}
+
+// retryTransport - repeats idempotent requests (and POST requests marked as safe) which failed with network
+// error or transient status. Delay grows exponentially with jitter, Retry-After response header is respected.
+type retryTransport struct {
+	next    http.RoundTripper
+	retries int
+	maxWait time.Duration
+}
+
+// Creates retry transport which repeats failed requests up to retries times sending them with next transport
+func newRetryTransport(next http.RoundTripper, retries int, maxWait time.Duration) *retryTransport {
+
+	transport := new(retryTransport)
+	transport.next = next
+	transport.retries = retries
+	transport.maxWait = maxWait
+
+	return transport
+}
+
+// Mark POST requests sent with returned context as safe to repeat (e.g. cancel requests)
+func withSafeRetry(ctx context.Context) context.Context {
+
+	return context.WithValue(ctx, safeRetryKey{}, true)
+}
+
+func (transport *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	if !isRetryable(req) {
+
+		return transport.next.RoundTrip(req)
+	}
+
+	for attempt := 0; ; attempt++ {
+
+		if attempt > 0 && req.GetBody != nil {
+
+			body, err := req.GetBody()
+			if err != nil {
+
+				return nil, err
+			}
+
+			req.Body = body
+		}
+
+		resp, err := transport.next.RoundTrip(req)
+
+		if attempt >= transport.retries || req.Context().Err() != nil {
+
+			return resp, err
+		}
+
+		reason := ""
+		if err != nil {
+
+			if !isNetworkError(err) {
+
+				return resp, err
+			}
+
+			reason = err.Error()
+
+		} else if retryStatuses[resp.StatusCode] {
+
+			reason = resp.Status
+
+		} else {
+
+			return resp, err
+		}
+
+		delay := transport.delay(attempt, resp)
+		debugf("Retry %d/%d of %s %s in %v: %s", attempt+1, transport.retries, req.Method, req.URL.Path,
+			delay, reason)
+
+		if resp != nil {
+
+			// body is drained so connection can be reused
+			io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 1<<16))
+			resp.Body.Close()
+		}
+
+		if err := sleepContext(req.Context(), delay); err != nil {
+
+			return nil, err
+		}
+	}
+}
+
+// Get delay before next retry: Retry-After header value or exponential delay with jitter, both limited by
+// max wait
+func (transport *retryTransport) delay(attempt int, resp *http.Response) time.Duration {
+
+	if resp != nil {
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+
+			if retryAfter > transport.maxWait {
+				return transport.maxWait
+			}
+
+			return retryAfter
+		}
+	}
+
+	delay := retryMinWait << uint(attempt)
+	if delay > transport.maxWait || delay <= 0 {
+		delay = transport.maxWait
+	}
+
+	// jitter spreads retries of parallel requests, delay is between half and full exponential delay
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// Check if request can be repeated: idempotent method or POST marked as safe, body must be rewindable
+func isRetryable(req *http.Request) bool {
+
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+
+		return false
+	}
+
+	switch req.Method {
+
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+
+	case http.MethodPost:
+		safe, _ := req.Context().Value(safeRetryKey{}).(bool)
+		return safe
+	}
+
+	return false
+}
+
+// Check if request failed with network error (e.g. refused or reset connection), certificate errors and
+// other permanent failures are not repeated
+func isNetworkError(err error) bool {
+
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+
+		return true
+	}
+
+	_, ok := err.(net.Error)
+
+	return ok
+}
+
+// Parse Retry-After header given as seconds or http date
+func parseRetryAfter(value string) (time.Duration, bool) {
+
+	if value == "" {
+
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+
+		delay := time.Until(date)
+		if delay < 0 {
+			delay = 0
+		}
+
+		return delay, true
+	}
+
+	return 0, false
+}
+
+// Parse retries setting, default number of retries is used when setting is empty
+func parseRetries(value string) (int, error) {
+
+	if value == "" {
+
+		return defaultRetries, nil
+	}
+
+	retries, err := strconv.Atoi(value)
+	if err != nil || retries < 0 {
+
+		return 0, cli.NewUsageError("Invalid retries: " + value)
+	}
+
+	return retries, nil
+}
@@ -0,0 +1,177 @@
+package telestream
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Start server which responds with scripted statuses, last status is repeated when script ends
+func newScriptedServer(statuses []int, headers map[string]string) (*httptest.Server, *int) {
+
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		status := statuses[len(statuses)-1]
+		if requests < len(statuses) {
+			status = statuses[requests]
+		}
+		requests++
+
+		for key, val := range headers {
+			w.Header().Set(key, val)
+		}
+		w.WriteHeader(status)
+	}))
+
+	return server, &requests
+}
+
+func Test_retryTransport(t *testing.T) {
+
+	defer func(minWait time.Duration) { retryMinWait = minWait }(retryMinWait)
+	retryMinWait = time.Millisecond
+
+	var testVector = []struct {
+		name     string
+		method   string
+		ctx      context.Context
+		retries  string
+		statuses []int
+		status   int
+		requests int
+	}{
+		{"success", "GET", context.Background(), "", []int{200}, 200, 1},
+		{"transient failures", "GET", context.Background(), "", []int{502, 503, 200}, 200, 3},
+		{"retries exhausted", "DELETE", context.Background(), "2", []int{500}, 500, 3},
+		{"retries disabled", "GET", context.Background(), "0", []int{502, 200}, 502, 1},
+		{"not transient failure", "GET", context.Background(), "", []int{404, 200}, 404, 1},
+		{"post", "POST", context.Background(), "", []int{502, 200}, 502, 1},
+		{"safe post", "POST", withSafeRetry(context.Background()), "", []int{502, 200}, 200, 2},
+	}
+
+	for _, testEl := range testVector {
+
+		t.Run(testEl.name, func(t *testing.T) {
+
+			server, requests := newScriptedServer(testEl.statuses, nil)
+			defer server.Close()
+
+			httpClient, err := NewHttpClient(ProfileSettings{Retries: testEl.retries, RetryMaxWait: "10ms"})
+			assert.Nil(t, err)
+
+			req, err := http.NewRequest(testEl.method, server.URL, strings.NewReader("{}"))
+			assert.Nil(t, err)
+
+			resp, err := httpClient.Do(req.WithContext(testEl.ctx))
+			assert.Nil(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, testEl.status, resp.StatusCode)
+			assert.Equal(t, testEl.requests, *requests)
+		})
+	}
+}
+
+func Test_retryTransport_retryAfter(t *testing.T) {
+
+	server, requests := newScriptedServer([]int{429, 200}, map[string]string{"Retry-After": "1"})
+	defer server.Close()
+
+	debug := new(bytes.Buffer)
+	SetLogOutput(debug, LogDebug)
+	defer SetLogOutput(nil, LogOff)
+
+	httpClient, err := NewHttpClient(ProfileSettings{RetryMaxWait: "100ms"})
+	assert.Nil(t, err)
+
+	start := time.Now()
+	resp, err := httpClient.Get(server.URL + "/flip/videos.json")
+	assert.Nil(t, err)
+	resp.Body.Close()
+
+	// Retry-After delay is limited by max wait
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Equal(t, 2, *requests)
+	assert.True(t, time.Since(start) >= 100*time.Millisecond)
+	assert.Contains(t, debug.String(), "DEBUG: Retry 1/3 of GET /flip/videos.json in 100ms: 429 Too Many Requests\n")
+}
+
+func Test_retryTransport_networkErrorAndCancel(t *testing.T) {
+
+	defer func(minWait time.Duration) { retryMinWait = minWait }(retryMinWait)
+	retryMinWait = time.Millisecond
+
+	server, requests := newScriptedServer([]int{503}, nil)
+	url := server.URL
+	server.Close()
+
+	next := &countingTransport{next: http.DefaultTransport}
+	httpClient := &http.Client{Transport: newRetryTransport(next, 2, 10*time.Millisecond)}
+
+	_, err := httpClient.Get(url)
+	assert.NotNil(t, err)
+	assert.Equal(t, 3, next.requests)
+
+	server, requests = newScriptedServer([]int{503}, nil)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	httpClient, err = NewHttpClient(ProfileSettings{Retries: "100", RetryMaxWait: "1s"})
+	assert.Nil(t, err)
+
+	req, err := http.NewRequest("GET", server.URL, nil)
+	assert.Nil(t, err)
+
+	_, err = httpClient.Do(req.WithContext(ctx))
+	assert.Equal(t, ExitTimeout, ExitCode(err))
+	assert.True(t, *requests < 100)
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+
+	delay, ok := parseRetryAfter("120")
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Minute, delay)
+
+	delay, ok = parseRetryAfter(time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), delay)
+
+	_, ok = parseRetryAfter("")
+	assert.False(t, ok)
+
+	_, ok = parseRetryAfter("soon")
+	assert.False(t, ok)
+}
+
+func Test_NewHttpClient_invalidRetries(t *testing.T) {
+
+	_, err := NewHttpClient(ProfileSettings{Retries: "-1"})
+	assert.Equal(t, ExitUsage, ExitCode(err))
+
+	_, err = NewHttpClient(ProfileSettings{RetryMaxWait: "soon"})
+	assert.Equal(t, ExitUsage, ExitCode(err))
+}
+
+// countingTransport - counts requests sent with next transport
+type countingTransport struct {
+	next     http.RoundTripper
+	requests int
+}
+
+func (transport *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	transport.requests++
+
+	return transport.next.RoundTrip(req)
+}
@@ -0,0 +1,309 @@
+package telestream
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"tcs-cli/cli"
+)
+
+// Get directory of files which hold tokens of rate limiters shared by tcs processes of user (in user cache
+// directory), replaced in tests
+var rateLimitStateDir = func() (string, error) {
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+
+		return "", err
+	}
+
+	return filepath.Join(cacheDir, "tcs"), nil
+}
+
+// output of warnings about rate limit state which cannot be shared, replaced in tests
+var rateLimitWarnings io.Writer = os.Stderr
+
+// waiting for lock of rate limit state file held by other process is limited, delay between attempts grows up
+// to max delay
+const (
+	rateLimitLockTimeout  = 10 * time.Second
+	rateLimitLockMaxDelay = 50 * time.Millisecond
+)
+
+// RateLimiter - token bucket shared by all requests of flip and tts clients. Bucket holds up to burst tokens
+// and is refilled with rate tokens per second, every request takes one token or waits until token is added.
+// When state path is set, tokens are kept in lock-protected file, so parallel tcs processes share the limit.
+type RateLimiter struct {
+	mutex     sync.Mutex
+	rate      float64
+	burst     float64
+	tokens    float64
+	last      time.Time
+	statePath string
+}
+
+// rateLimitState - tokens of rate limiter stored in state file and time when they were counted
+type rateLimitState struct {
+	Tokens float64   `json:"tokens"`
+	Last   time.Time `json:"last"`
+}
+
+// rateLimitTransport - sends requests with next transport when rate limiter allows it, number of requests in
+// flight (sent and not yet read responses) is limited by burst of rate limiter
+type rateLimitTransport struct {
+	next     http.RoundTripper
+	limiter  *RateLimiter
+	inFlight chan struct{}
+}
+
+// inFlightBody - body of response which frees in flight slot of request when it is read or closed
+type inFlightBody struct {
+	io.ReadCloser
+	release func()
+}
+
+// Creates rate limiter which allows rate requests per second, up to burst requests can be sent at once
+func NewRateLimiter(rate float64, burst int) *RateLimiter {
+
+	limiter := new(RateLimiter)
+	limiter.rate = rate
+	limiter.burst = math.Max(float64(burst), 1)
+	limiter.tokens = limiter.burst
+	limiter.last = time.Now()
+
+	return limiter
+}
+
+// Creates rate limiter with tokens kept in state file, so the limit is shared by processes using the same file
+func NewSharedRateLimiter(rate float64, burst int, statePath string) *RateLimiter {
+
+	limiter := NewRateLimiter(rate, burst)
+	limiter.statePath = statePath
+
+	return limiter
+}
+
+// Wait until request can be sent or context is done, token is taken when request is allowed
+func (limiter *RateLimiter) Wait(ctx context.Context) error {
+
+	// token is reserved, so concurrent requests wait for next tokens
+	delay := limiter.take(1)
+
+	if delay == 0 {
+
+		return nil
+	}
+
+	if err := sleepContext(ctx, delay); err != nil {
+
+		// reserved token is given back, request is not sent
+		limiter.take(-1)
+
+		return err
+	}
+
+	return nil
+}
+
+// Take count tokens from bucket (negative count gives tokens back), returns delay after which taken tokens
+// are available. Bucket of state file is used when it is set, limit is kept in process only when file cannot
+// be used.
+func (limiter *RateLimiter) take(count float64) time.Duration {
+
+	limiter.mutex.Lock()
+	defer limiter.mutex.Unlock()
+
+	now := time.Now()
+
+	if limiter.statePath != "" {
+
+		delay, err := limiter.takeShared(count, now)
+		if err == nil {
+
+			return delay
+		}
+
+		fmt.Fprintln(rateLimitWarnings, "Warning: Rate limit: "+err.Error()+
+			" (limit is not shared with other tcs processes)")
+		limiter.statePath = ""
+	}
+
+	var delay time.Duration
+	limiter.tokens, delay = limiter.refill(limiter.tokens, limiter.last, now, count)
+	limiter.last = now
+
+	return delay
+}
+
+// Take count tokens from bucket of state file, file is locked while it is updated
+func (limiter *RateLimiter) takeShared(count float64, now time.Time) (time.Duration, error) {
+
+	unlock, err := lockFile(limiter.statePath + ".lock")
+	if err != nil {
+
+		return 0, err
+	}
+	defer unlock()
+
+	// missing or invalid state file means full bucket
+	state := rateLimitState{Tokens: limiter.burst, Last: now}
+
+	content, err := ioutil.ReadFile(limiter.statePath)
+	if err != nil && !os.IsNotExist(err) {
+
+		return 0, err
+	}
+
+	if err == nil && json.Unmarshal(content, &state) != nil {
+
+		state = rateLimitState{Tokens: limiter.burst, Last: now}
+	}
+
+	var delay time.Duration
+	state.Tokens, delay = limiter.refill(state.Tokens, state.Last, now, count)
+	state.Last = now
+
+	content, err = json.Marshal(&state)
+	if err != nil {
+
+		return 0, err
+	}
+
+	return delay, ioutil.WriteFile(limiter.statePath, content, 0600)
+}
+
+// Add tokens refilled since last to bucket and take count tokens, returns tokens left (negative when tokens are
+// reserved) and delay after which taken tokens are available
+func (limiter *RateLimiter) refill(tokens float64, last time.Time, now time.Time, count float64) (float64,
+	time.Duration) {
+
+	tokens = math.Min(limiter.burst, tokens+math.Max(now.Sub(last).Seconds(), 0)*limiter.rate)
+	tokens = math.Min(limiter.burst, tokens-count)
+
+	if tokens >= 0 {
+
+		return tokens, 0
+	}
+
+	return tokens, time.Duration(-tokens / limiter.rate * float64(time.Second))
+}
+
+// Creates transport which sends requests allowed by rate limiter with next transport
+func newRateLimitTransport(next http.RoundTripper, limiter *RateLimiter) *rateLimitTransport {
+
+	return &rateLimitTransport{next: next, limiter: limiter, inFlight: make(chan struct{}, int(limiter.burst))}
+}
+
+func (transport *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	// slot is taken before token, so requests waiting for slot do not use up tokens
+	select {
+	case transport.inFlight <- struct{}{}:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+
+	releaseOnce := sync.Once{}
+	release := func() {
+		releaseOnce.Do(func() { <-transport.inFlight })
+	}
+
+	if err := transport.limiter.Wait(req.Context()); err != nil {
+
+		release()
+		return nil, err
+	}
+
+	resp, err := transport.next.RoundTrip(req)
+	if err != nil {
+
+		release()
+		return nil, err
+	}
+
+	resp.Body = &inFlightBody{ReadCloser: resp.Body, release: release}
+
+	return resp, nil
+}
+
+func (body *inFlightBody) Read(p []byte) (int, error) {
+
+	n, err := body.ReadCloser.Read(p)
+	if err == io.EOF {
+
+		body.release()
+	}
+
+	return n, err
+}
+
+func (body *inFlightBody) Close() error {
+
+	body.release()
+
+	return body.ReadCloser.Close()
+}
+
+// Get path of rate limit state file of account (api key) and rate, processes with the same key and rate share
+// the file. Key is hashed, so it is not stored in file name.
+func rateLimitStatePath(apiKey string, rate float64) (string, error) {
+
+	stateDir, err := rateLimitStateDir()
+	if err != nil {
+
+		return "", err
+	}
+
+	hash := sha256.Sum256([]byte(apiKey + "\n" + strconv.FormatFloat(rate, 'g', -1, 64)))
+
+	return filepath.Join(stateDir, "rate-limit-"+hex.EncodeToString(hash[:8])+".json"), nil
+}
+
+// Parse rps setting (requests per second), nil rate limiter (no limit) is returned when setting is empty or 0.
+// Burst of rate limiter is one second of requests. Limit is shared by tcs processes of user which use the same
+// api key and rate by state file in user cache directory.
+func parseRateLimit(value string, apiKey string) (*RateLimiter, error) {
+
+	if value == "" {
+
+		return nil, nil
+	}
+
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil || rate < 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+
+		return nil, cli.NewUsageError("Invalid rps: " + value)
+	}
+
+	if rate == 0 {
+
+		return nil, nil
+	}
+
+	if !sharedRateLimitSupported {
+
+		return NewRateLimiter(rate, int(rate)), nil
+	}
+
+	statePath, err := rateLimitStatePath(apiKey, rate)
+	if err != nil {
+
+		fmt.Fprintln(rateLimitWarnings, "Warning: Rate limit: "+err.Error()+
+			" (limit is not shared with other tcs processes)")
+		return NewRateLimiter(rate, int(rate)), nil
+	}
+
+	return NewSharedRateLimiter(rate, int(rate), statePath), nil
+}
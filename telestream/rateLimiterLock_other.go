@@ -0,0 +1,17 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package telestream
+
+import (
+	"errors"
+)
+
+// state file of rate limiter cannot be locked, so the limit is kept in process
+const sharedRateLimitSupported = false
+
+// File locks are not supported, rate limiter keeps tokens in process
+func lockFile(path string) (func(), error) {
+
+	return nil, errors.New("File locks are not supported: " + path)
+}
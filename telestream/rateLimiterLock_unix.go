@@ -0,0 +1,62 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package telestream
+
+import (
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// state file of rate limiter can be locked, so the limit is shared by tcs processes
+const sharedRateLimitSupported = true
+
+// Lock file with exclusive flock, waits with growing delay while other process holds the lock. Lock is released
+// by returned function or by OS when process is killed, so no stale lock is left.
+func lockFile(path string) (func(), error) {
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+
+		return nil, err
+	}
+
+	deadline := time.Now().Add(rateLimitLockTimeout)
+	delay := time.Millisecond
+
+	for {
+
+		err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+
+			return func() {
+				syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+				file.Close()
+			}, nil
+		}
+
+		if err != syscall.EWOULDBLOCK && err != syscall.EINTR {
+
+			file.Close()
+			return nil, err
+		}
+
+		if time.Now().Add(delay).After(deadline) {
+
+			file.Close()
+			return nil, errors.New("File is locked: " + path)
+		}
+
+		time.Sleep(delay)
+		delay = time.Duration(math.Min(float64(2*delay), float64(rateLimitLockMaxDelay)))
+	}
+}
@@ -0,0 +1,262 @@
+package telestream
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RateLimiter_Wait(t *testing.T) {
+
+	limiter := NewRateLimiter(50, 2)
+
+	// burst is allowed at once, next 3 requests wait for 20ms each
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		assert.Nil(t, limiter.Wait(context.Background()))
+	}
+
+	elapsed := time.Since(start)
+	assert.True(t, elapsed >= 60*time.Millisecond, elapsed.String())
+	assert.True(t, elapsed < time.Second, elapsed.String())
+}
+
+func Test_RateLimiter_concurrentWait(t *testing.T) {
+
+	limiter := NewRateLimiter(100, 1)
+
+	start := time.Now()
+	wg := sync.WaitGroup{}
+	for i := 0; i < 6; i++ {
+
+		wg.Add(1)
+		go func() {
+
+			defer wg.Done()
+			assert.Nil(t, limiter.Wait(context.Background()))
+		}()
+	}
+	wg.Wait()
+
+	assert.True(t, time.Since(start) >= 50*time.Millisecond)
+}
+
+func Test_RateLimiter_canceledWait(t *testing.T) {
+
+	limiter := NewRateLimiter(1, 1)
+	assert.Nil(t, limiter.Wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	assert.Equal(t, context.DeadlineExceeded, limiter.Wait(ctx))
+
+	// token of canceled wait is given back
+	assert.InDelta(t, 0, limiter.tokens, 0.1)
+}
+
+// Replace directory of rate limit state files and output of their warnings, returned function restores them
+func useRateLimitState(dir string, warnings io.Writer) func() {
+
+	stateDir, stateWarnings := rateLimitStateDir, rateLimitWarnings
+
+	rateLimitStateDir = func() (string, error) { return dir, nil }
+	rateLimitWarnings = warnings
+
+	return func() { rateLimitStateDir, rateLimitWarnings = stateDir, stateWarnings }
+}
+
+func Test_RateLimiter_shared(t *testing.T) {
+
+	if !sharedRateLimitSupported {
+		t.Skip("file locks are not supported")
+	}
+
+	dir, err := ioutil.TempDir("", "tcs-rate")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	statePath := filepath.Join(dir, "tcs", "rate-limit.json")
+
+	// limiters of two processes take tokens from one bucket, burst of the first one is used up
+	first := NewSharedRateLimiter(50, 2, statePath)
+	second := NewSharedRateLimiter(50, 2, statePath)
+
+	start := time.Now()
+	assert.Nil(t, first.Wait(context.Background()))
+	assert.Nil(t, first.Wait(context.Background()))
+	assert.Nil(t, second.Wait(context.Background()))
+
+	elapsed := time.Since(start)
+	assert.True(t, elapsed >= 15*time.Millisecond, elapsed.String())
+	assert.FileExists(t, statePath)
+
+	// state is not read while other process holds the lock
+	unlock, err := lockFile(statePath + ".lock")
+	assert.Nil(t, err)
+	time.AfterFunc(30*time.Millisecond, unlock)
+
+	start = time.Now()
+	assert.Nil(t, second.Wait(context.Background()))
+
+	elapsed = time.Since(start)
+	assert.True(t, elapsed >= 30*time.Millisecond, elapsed.String())
+	assert.Equal(t, statePath, second.statePath)
+}
+
+func Test_rateLimitStatePath(t *testing.T) {
+
+	defer useRateLimitState("/cache/tcs", ioutil.Discard)()
+
+	statePath, err := rateLimitStatePath("key", 10)
+	assert.Nil(t, err)
+	assert.Equal(t, "/cache/tcs", filepath.Dir(statePath))
+	assert.Regexp(t, `^rate-limit-[0-9a-f]{16}\.json$`, filepath.Base(statePath))
+	assert.NotContains(t, statePath, "key")
+
+	// the same account and rate share the state file, other accounts and rates use their own files
+	samePath, _ := rateLimitStatePath("key", 10)
+	otherKeyPath, _ := rateLimitStatePath("other key", 10)
+	otherRatePath, _ := rateLimitStatePath("key", 5)
+
+	assert.Equal(t, statePath, samePath)
+	assert.NotEqual(t, statePath, otherKeyPath)
+	assert.NotEqual(t, statePath, otherRatePath)
+}
+
+func Test_RateLimiter_sharedStateNotUsable(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "tcs-rate")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	// state directory cannot be created under file
+	file := filepath.Join(dir, "file")
+	assert.Nil(t, ioutil.WriteFile(file, nil, 0600))
+
+	warnings := new(bytes.Buffer)
+	defer func(output io.Writer) { rateLimitWarnings = output }(rateLimitWarnings)
+	rateLimitWarnings = warnings
+
+	limiter := NewSharedRateLimiter(50, 1, filepath.Join(file, "rate-limit.json"))
+	assert.Nil(t, limiter.Wait(context.Background()))
+	assert.Nil(t, limiter.Wait(context.Background()))
+
+	assert.Equal(t, 1, strings.Count(warnings.String(), "Warning: Rate limit: "))
+	assert.Contains(t, warnings.String(), "(limit is not shared with other tcs processes)")
+	assert.Equal(t, "", limiter.statePath)
+}
+
+func Test_rateLimitTransport_inFlight(t *testing.T) {
+
+	mutex := sync.Mutex{}
+	inFlight, maxInFlight := 0, 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		mutex.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mutex.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mutex.Lock()
+		inFlight--
+		mutex.Unlock()
+	}))
+	defer server.Close()
+
+	// rate does not delay requests, only one request is in flight at once
+	httpClient := &http.Client{Transport: newRateLimitTransport(http.DefaultTransport, NewRateLimiter(1000, 1))}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+
+		wg.Add(1)
+		go func() {
+
+			defer wg.Done()
+
+			resp, err := httpClient.Get(server.URL)
+			assert.Nil(t, err)
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, maxInFlight)
+
+	// request waiting for slot is canceled with its context
+	transport := newRateLimitTransport(http.DefaultTransport, NewRateLimiter(1000, 1))
+	transport.inFlight <- struct{}{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	assert.Nil(t, err)
+
+	_, err = transport.RoundTrip(req.WithContext(ctx))
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func Test_NewHttpClient_rateLimit(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "tcs-rate")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	defer useRateLimitState(dir, ioutil.Discard)()
+
+	server, requests := newScriptedServer([]int{200}, nil)
+	defer server.Close()
+
+	httpClient, err := NewHttpClient(ProfileSettings{Rps: "20", ApiKey: "key"})
+	assert.Nil(t, err)
+
+	// burst of 20 requests is followed by requests sent every 50ms
+	start := time.Now()
+	for i := 0; i < 23; i++ {
+
+		resp, err := httpClient.Get(server.URL)
+		assert.Nil(t, err)
+		resp.Body.Close()
+	}
+
+	assert.Equal(t, 23, *requests)
+	assert.True(t, time.Since(start) >= 150*time.Millisecond)
+	if sharedRateLimitSupported {
+
+		statePath, _ := rateLimitStatePath("key", 20)
+		assert.FileExists(t, statePath)
+	}
+
+	for _, rps := range []string{"fast", "-1"} {
+
+		_, err = NewHttpClient(ProfileSettings{Rps: rps})
+		assert.Equal(t, ExitUsage, ExitCode(err))
+	}
+
+	httpClient, err = NewHttpClient(ProfileSettings{Rps: "0"})
+	assert.Nil(t, err)
+	_, limited := httpClient.Transport.(*retryTransport).next.(*rateLimitTransport)
+	assert.False(t, limited)
+
+	httpClient, err = NewHttpClient(ProfileSettings{Rps: "0.5"})
+	assert.Nil(t, err)
+	assert.IsType(t, &rateLimitTransport{}, httpClient.Transport.(*retryTransport).next)
+}
@@ -0,0 +1,400 @@
+package telestream
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// stores of profile api keys: plain text credentials file, OS keyring or credentials file with api key
+// encrypted by passphrase
+const (
+	StoreFile      = "file"
+	StoreKeyring   = "keyring"
+	StoreEncrypted = "encrypted"
+)
+
+// environment variable which holds passphrase of encrypted api keys
+const PassphraseEnv = "TCS_PASSPHRASE"
+
+// name of keyring service which holds api keys, profile name is used as account
+const keyringService = "tcs-cli"
+
+// prefix and key derivation settings of encrypted api key
+const encryptedKeyPrefix = "v1:"
+const encryptionSaltSize = 16
+const encryptionIterations = 100000
+
+// SecretStore - stores api keys of profiles outside of plain text credentials file
+type SecretStore interface {
+	Get(profile string) (string, error)
+	Set(profile string, secret string) error
+	Delete(profile string) error
+}
+
+// commandKeyring - OS keyring accessed with system tool: security on macOS, secret-tool (libsecret) elsewhere
+type commandKeyring struct {
+	service string
+}
+
+// encryptedStore - keeps api keys in credentials file encrypted with AES-GCM, key is derived from passphrase
+type encryptedStore struct {
+	config     *ConfigFile
+	passphrase func() (string, error)
+}
+
+// keyring used by keyring store, replaced in tests
+var keyring SecretStore = &commandKeyring{keyringService}
+
+// passphrase of encrypted api keys: TCS_PASSPHRASE environment variable or terminal prompt, replaced in tests
+var passphraseSource = readPassphrase
+
+// Check if keyring can be used, keyring without availability check is always available
+func keyringAvailable() bool {
+
+	if checker, ok := keyring.(interface{ available() bool }); ok {
+
+		return checker.available()
+	}
+
+	return true
+}
+
+// Check if OS keyring tool is installed
+func (store *commandKeyring) available() bool {
+
+	_, err := exec.LookPath(store.tool())
+
+	return err == nil
+}
+
+func (store *commandKeyring) tool() string {
+
+	if runtime.GOOS == "darwin" {
+
+		return "security"
+	}
+
+	return "secret-tool"
+}
+
+// Get api key of profile from keyring
+func (store *commandKeyring) Get(profile string) (string, error) {
+
+	args := []string{"lookup", "service", store.service, "profile", profile}
+	if runtime.GOOS == "darwin" {
+		args = []string{"find-generic-password", "-s", store.service, "-a", profile, "-w"}
+	}
+
+	secret, err := store.run(nil, args...)
+	if err != nil {
+
+		return "", errors.New("Cannot read api key of profile " + profile + " from keyring: " + err.Error())
+	}
+
+	return strings.TrimRight(secret, "\r\n"), nil
+}
+
+// Save api key of profile in keyring, existing api key is replaced
+func (store *commandKeyring) Set(profile string, secret string) error {
+
+	args, input := store.setCommand(runtime.GOOS, profile, secret)
+
+	_, err := store.run(strings.NewReader(input), args...)
+
+	// security -i does not fail when its command fails, saved api key is read back
+	if err == nil && runtime.GOOS == "darwin" {
+		if saved, getErr := store.Get(profile); getErr != nil || saved != secret {
+
+			err = errors.New("api key not saved")
+		}
+	}
+
+	if err != nil {
+
+		return errors.New("Cannot save api key of profile " + profile + " in keyring: " + err.Error())
+	}
+
+	return nil
+}
+
+// Get keyring tool arguments and input which save api key of profile. Api key is passed on stdin, so it is
+// not visible in process list: secret-tool reads it, security reads whole command in interactive mode.
+func (store *commandKeyring) setCommand(goos string, profile string, secret string) ([]string, string) {
+
+	if goos == "darwin" {
+
+		return []string{"-i"}, "add-generic-password -U -s " + quoteKeyringArg(store.service) + " -a " +
+			quoteKeyringArg(profile) + " -w " + quoteKeyringArg(secret) + "\n"
+	}
+
+	return []string{"store", "--label", store.service + " " + profile, "service", store.service, "profile",
+		profile}, secret
+}
+
+// Quote argument of security interactive mode command
+func quoteKeyringArg(arg string) string {
+
+	return "\"" + strings.NewReplacer("\\", "\\\\", "\"", "\\\"").Replace(arg) + "\""
+}
+
+// Remove api key of profile from keyring
+func (store *commandKeyring) Delete(profile string) error {
+
+	args := []string{"clear", "service", store.service, "profile", profile}
+	if runtime.GOOS == "darwin" {
+		args = []string{"delete-generic-password", "-s", store.service, "-a", profile}
+	}
+
+	_, err := store.run(nil, args...)
+
+	return err
+}
+
+// Run keyring tool, error holds tool error output
+func (store *commandKeyring) run(stdin *strings.Reader, args ...string) (string, error) {
+
+	cmd := exec.Command(store.tool(), args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+
+			return "", errors.New(message)
+		}
+
+		return "", err
+	}
+
+	return stdout.String(), nil
+}
+
+// Get api key of profile decrypted with passphrase
+func (store *encryptedStore) Get(profile string) (string, error) {
+
+	encrypted := store.config.value(profile, "api_key_encrypted")
+	if encrypted == "" {
+
+		return "", nil
+	}
+
+	passphrase, err := store.passphrase()
+	if err != nil {
+
+		return "", err
+	}
+
+	secret, err := decryptSecret(encrypted, passphrase)
+	if err != nil {
+
+		return "", errors.New("Cannot decrypt api key of profile " + profile + ": " + err.Error())
+	}
+
+	return secret, nil
+}
+
+// Encrypt api key of profile with passphrase and keep it in credentials file (file is saved by caller)
+func (store *encryptedStore) Set(profile string, secret string) error {
+
+	passphrase, err := store.passphrase()
+	if err != nil {
+
+		return err
+	}
+
+	if passphrase == "" {
+
+		return errors.New("Passphrase of encrypted api key cannot be empty")
+	}
+
+	encrypted, err := encryptSecret(secret, passphrase)
+	if err != nil {
+
+		return err
+	}
+
+	store.config.SetSetting(profile, "api_key_encrypted", encrypted)
+
+	return nil
+}
+
+// Remove encrypted api key of profile from credentials file
+func (store *encryptedStore) Delete(profile string) error {
+
+	store.config.SetSetting(profile, "api_key_encrypted", "")
+
+	return nil
+}
+
+// Get passphrase from TCS_PASSPHRASE environment variable or ask for it when stdin is terminal
+func readPassphrase() (string, error) {
+
+	if passphrase := os.Getenv(PassphraseEnv); passphrase != "" {
+
+		return passphrase, nil
+	}
+
+	if !isTerminal(os.Stdin) {
+
+		return "", errors.New("Passphrase of encrypted api key is required, set " + PassphraseEnv +
+			" environment variable")
+	}
+
+	fmt.Fprint(os.Stderr, "Passphrase: ")
+
+	// echo is turned off where stty is available
+	if err := setTerminalEcho(false); err == nil {
+		defer func() {
+			setTerminalEcho(true)
+			fmt.Fprintln(os.Stderr)
+		}()
+	}
+
+	passphrase, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && passphrase == "" {
+
+		return "", errors.New("Cannot read passphrase: " + err.Error())
+	}
+
+	return strings.TrimRight(passphrase, "\r\n"), nil
+}
+
+func setTerminalEcho(on bool) error {
+
+	mode := "-echo"
+	if on {
+		mode = "echo"
+	}
+
+	cmd := exec.Command("stty", mode)
+	cmd.Stdin = os.Stdin
+
+	return cmd.Run()
+}
+
+// Encrypt secret with AES-256-GCM, key is derived from passphrase with PBKDF2 (random salt), result holds
+// salt, nonce and cipher text
+func encryptSecret(secret string, passphrase string) (string, error) {
+
+	salt := make([]byte, encryptionSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+
+		return "", err
+	}
+
+	aead, err := newSecretCipher(passphrase, salt)
+	if err != nil {
+
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+
+		return "", err
+	}
+
+	content := append(append(salt, nonce...), aead.Seal(nil, nonce, []byte(secret), nil)...)
+
+	return encryptedKeyPrefix + base64.StdEncoding.EncodeToString(content), nil
+}
+
+// Decrypt secret encrypted by encryptSecret, wrong passphrase fails authentication of cipher text
+func decryptSecret(encrypted string, passphrase string) (string, error) {
+
+	if !strings.HasPrefix(encrypted, encryptedKeyPrefix) {
+
+		return "", errors.New("unknown format of encrypted api key")
+	}
+
+	content, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(encrypted, encryptedKeyPrefix))
+	if err != nil || len(content) < encryptionSaltSize {
+
+		return "", errors.New("invalid encrypted api key")
+	}
+
+	aead, err := newSecretCipher(passphrase, content[:encryptionSaltSize])
+	if err != nil {
+
+		return "", err
+	}
+
+	content = content[encryptionSaltSize:]
+	if len(content) < aead.NonceSize() {
+
+		return "", errors.New("invalid encrypted api key")
+	}
+
+	secret, err := aead.Open(nil, content[:aead.NonceSize()], content[aead.NonceSize():], nil)
+	if err != nil {
+
+		return "", errors.New("wrong passphrase")
+	}
+
+	return string(secret), nil
+}
+
+func newSecretCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+
+	block, err := aes.NewCipher(pbkdf2Sha256([]byte(passphrase), salt, encryptionIterations, 32))
+	if err != nil {
+
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// Derive key of given length from password (PBKDF2 with HMAC-SHA256, RFC 8018)
+func pbkdf2Sha256(password []byte, salt []byte, iterations int, keyLen int) []byte {
+
+	prf := hmac.New(sha256.New, password)
+	key := []byte{}
+
+	for block := uint32(1); len(key) < keyLen; block++ {
+
+		blockIdx := make([]byte, 4)
+		binary.BigEndian.PutUint32(blockIdx, block)
+
+		prf.Reset()
+		prf.Write(salt)
+		prf.Write(blockIdx)
+		u := prf.Sum(nil)
+		t := append([]byte{}, u...)
+
+		for i := 1; i < iterations; i++ {
+
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(nil)
+
+			for j := range t {
+				t[j] ^= u[j]
+			}
+		}
+
+		key = append(key, t...)
+	}
+
+	return key[:keyLen]
+}
@@ -0,0 +1,208 @@
+package telestream
+
+import (
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// keyringMock - keyring which keeps secrets in memory
+type keyringMock struct {
+	secrets     map[string]string
+	unavailable bool
+}
+
+func (store *keyringMock) available() bool {
+
+	return !store.unavailable
+}
+
+func (store *keyringMock) Get(profile string) (string, error) {
+
+	secret, ok := store.secrets[profile]
+	if !ok {
+
+		return "", errors.New("no secret")
+	}
+
+	return secret, nil
+}
+
+func (store *keyringMock) Set(profile string, secret string) error {
+
+	store.secrets[profile] = secret
+
+	return nil
+}
+
+func (store *keyringMock) Delete(profile string) error {
+
+	delete(store.secrets, profile)
+
+	return nil
+}
+
+func Test_pbkdf2Sha256(t *testing.T) {
+
+	// RFC 7914 PBKDF2-HMAC-SHA256 test vector
+	expected := "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc" +
+		"49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"
+
+	assert.Equal(t, expected, hex.EncodeToString(pbkdf2Sha256([]byte("passwd"), []byte("salt"), 1, 64)))
+}
+
+func Test_encryptSecret(t *testing.T) {
+
+	encrypted, err := encryptSecret("apikey", "passphrase")
+	assert.Nil(t, err)
+	assert.NotContains(t, encrypted, "apikey")
+
+	secret, err := decryptSecret(encrypted, "passphrase")
+	assert.Nil(t, err)
+	assert.Equal(t, "apikey", secret)
+
+	_, err = decryptSecret(encrypted, "other")
+	assert.Equal(t, "wrong passphrase", err.Error())
+
+	_, err = decryptSecret("apikey", "passphrase")
+	assert.NotNil(t, err)
+
+	_, err = decryptSecret(encryptedKeyPrefix+"AAAA", "passphrase")
+	assert.NotNil(t, err)
+}
+
+func Test_commandKeyring_setCommand(t *testing.T) {
+
+	store := &commandKeyring{keyringService}
+	secret := "secret\"key\\1234"
+
+	var testVector = []struct {
+		goos  string
+		args  []string
+		input string
+	}{
+		{"linux", []string{"store", "--label", keyringService + " staging", "service", keyringService, "profile",
+			"staging"}, secret},
+		{"darwin", []string{"-i"}, "add-generic-password -U -s \"" + keyringService +
+			"\" -a \"staging\" -w \"secret\\\"key\\\\1234\"\n"},
+	}
+
+	for _, testEl := range testVector {
+		t.Run(testEl.goos, func(t *testing.T) {
+
+			args, input := store.setCommand(testEl.goos, "staging", secret)
+
+			assert.Equal(t, testEl.args, args)
+			assert.Equal(t, testEl.input, input)
+
+			// api key is never visible in process list
+			for _, arg := range args {
+				assert.NotContains(t, arg, "1234")
+			}
+		})
+	}
+}
+
+func Test_ConfigFile_SaveApiKey(t *testing.T) {
+
+	savedKeyring := keyring
+	savedPassphrase := passphraseSource
+	defer func() {
+		keyring = savedKeyring
+		passphraseSource = savedPassphrase
+	}()
+
+	mock := &keyringMock{secrets: map[string]string{}}
+	keyring = mock
+	passphraseSource = func() (string, error) { return "passphrase", nil }
+
+	dir, err := ioutil.TempDir("", "tcs-config")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, ConfigFileName)
+	config, _ := LoadConfigFile(path)
+
+	var testVector = []struct {
+		name        string
+		store       string
+		unavailable bool
+		usedStore   string
+		fileKey     string
+		keyringKey  string
+	}{
+		{"file", "", false, StoreFile, "key1", ""},
+		{"keyring", StoreKeyring, false, StoreKeyring, "", "key2"},
+		{"keyring not available", StoreKeyring, true, StoreEncrypted, "", ""},
+		{"back to file", StoreFile, false, StoreFile, "key4", ""},
+	}
+
+	for idx, testEl := range testVector {
+
+		t.Run(testEl.name, func(t *testing.T) {
+
+			apiKey := "key" + strconv.Itoa(idx+1)
+			mock.unavailable = testEl.unavailable
+
+			store, err := config.SaveApiKey("prod", apiKey, testEl.store)
+			assert.Nil(t, err)
+			assert.Equal(t, testEl.usedStore, store)
+			assert.Nil(t, config.Save())
+
+			loaded, err := LoadConfigFile(path)
+			assert.Nil(t, err)
+			assert.Equal(t, testEl.usedStore, loaded.Store("prod"))
+			assert.Equal(t, testEl.fileKey, loaded.ApiKey("prod"))
+			assert.Equal(t, testEl.keyringKey, mock.secrets["prod"])
+
+			readKey, err := loaded.ReadApiKey("prod")
+			assert.Nil(t, err)
+			assert.Equal(t, apiKey, readKey)
+
+			content, _ := ioutil.ReadFile(path)
+			assert.NotContains(t, string(content), "key2")
+			assert.NotContains(t, string(content), "key3")
+		})
+	}
+
+	_, err = config.SaveApiKey("prod", "key", "vault")
+	assert.Equal(t, ExitUsage, ExitCode(err))
+
+	config.SaveApiKey("staging", "key", StoreKeyring)
+	assert.Nil(t, config.RemoveProfile("staging"))
+	assert.Empty(t, mock.secrets)
+
+	passphraseSource = func() (string, error) { return "", errors.New("no passphrase") }
+	config.SetSetting("prod", "store", StoreEncrypted)
+	config.SetSetting("prod", "api_key_encrypted", "v1:AAAA")
+	_, err = config.ReadApiKey("prod")
+	assert.Equal(t, "no passphrase", err.Error())
+}
+
+func Test_CheckConfigFilePermissions(t *testing.T) {
+
+	dir, err := ioutil.TempDir("", "tcs-config")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, ConfigFileName)
+	assert.Nil(t, CheckConfigFilePermissions(path))
+
+	config, _ := LoadConfigFile(path)
+	config.SetApiKey(DefaultProfile, "key")
+	assert.Nil(t, config.Save())
+
+	fileInfo, err := os.Stat(path)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0600), fileInfo.Mode().Perm())
+	assert.Nil(t, CheckConfigFilePermissions(path))
+
+	os.Chmod(path, 0644)
+	assert.Contains(t, CheckConfigFilePermissions(path).Error(), "chmod 600")
+}
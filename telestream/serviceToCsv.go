@@ -0,0 +1,120 @@
+package telestream
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var ServiceToCsv = NewServiceToCsv(os.Stdout, ',')
+
+type serviceToCsv struct {
+	writer    io.Writer
+	errWriter io.Writer
+	separator rune
+}
+
+// Creates new csv printer which writes every result as csv records to given writer, errors are written
+// to stderr
+func NewServiceToCsv(writer io.Writer, separator rune) *serviceToCsv {
+
+	printer := new(serviceToCsv)
+	printer.writer = writer
+	printer.errWriter = os.Stderr
+	printer.separator = separator
+
+	return printer
+}
+
+func (printer *serviceToCsv) writeRecords(records [][]string) {
+
+	printer.writeRecordsTo(printer.writer, records)
+}
+
+func (printer *serviceToCsv) writeRecordsTo(writer io.Writer, records [][]string) {
+
+	csvWriter := csv.NewWriter(writer)
+	csvWriter.Comma = printer.separator
+
+	csvWriter.WriteAll(records)
+}
+
+func (printer *serviceToCsv) printError(fName string, err error) {
+
+	errorMsg := ""
+	if err != nil {
+		errorMsg = err.Error()
+	}
+
+	printer.writeRecordsTo(printer.errWriter, [][]string{{"COMMAND", "ERROR"}, {fName, errorMsg}})
+}
+
+// Print structure as header with field names and single record with field values
+func (printer *serviceToCsv) printStructContent(j interface{}) {
+
+	names, values, ok := structContent(j)
+	if !ok {
+
+		// not a structure (e.g. query result) - print each value as separate record
+		records := [][]string{}
+		for _, value := range listContent(j) {
+			if value == nil {
+				records = append(records, []string{"null"})
+			} else {
+				records = append(records, []string{fmt.Sprint(value)})
+			}
+		}
+
+		printer.writeRecords(records)
+		return
+	}
+
+	record := []string{}
+	for _, value := range values {
+		record = append(record, fmt.Sprint(value))
+	}
+
+	printer.writeRecords([][]string{names, record})
+}
+
+// Print informational message to stderr, so stdout holds only csv records of command results
+func (printer *serviceToCsv) printInfo(info string) {
+
+	printer.writeRecordsTo(printer.errWriter, [][]string{{info}})
+}
+
+// Print table columns as header and table rows as records
+func (printer *serviceToCsv) printTable(colNames []interface{}, rows [][]interface{}) {
+
+	records := [][]string{}
+
+	if len(colNames) > 0 {
+
+		header := []string{}
+		for _, name := range colNames {
+			header = append(header, strings.TrimSpace(fmt.Sprint(name)))
+		}
+
+		records = append(records, header)
+	}
+
+	for _, row := range rows {
+
+		record := []string{}
+		for _, val := range row {
+			record = append(record, fmt.Sprint(val))
+		}
+
+		records = append(records, record)
+	}
+
+	printer.writeRecords(records)
+}
+
+// Print collection returned by service with the same columns as table printer
+func (printer *serviceToCsv) printCollection(j interface{}, colNames []interface{}, rows [][]interface{}) {
+
+	printer.printTable(colNames, rows)
+}
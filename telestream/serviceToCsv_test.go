@@ -0,0 +1,61 @@
+package telestream
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Creates csv printer which writes results and errors to the same buffer
+func newTestCsvPrinter(buffer *bytes.Buffer) *serviceToCsv {
+
+	printer := NewServiceToCsv(buffer, ',')
+	printer.errWriter = buffer
+
+	return printer
+}
+
+func Test_serviceToCsv_prints(t *testing.T) {
+
+	buffer := new(bytes.Buffer)
+	printer := newTestCsvPrinter(buffer)
+
+	printer.printError("name", errors.New("some error"))
+	assert.Equal(t, "COMMAND,ERROR\nname,some error\n", buffer.String())
+
+	// info is printed to stderr
+	buffer.Reset()
+	errBuffer := new(bytes.Buffer)
+	printer.errWriter = errBuffer
+	printer.printInfo("info, with comma")
+	assert.Equal(t, "", buffer.String())
+	assert.Equal(t, "\"info, with comma\"\n", errBuffer.String())
+	printer.errWriter = buffer
+
+	buffer.Reset()
+	printer.printTable(nil, nil)
+	assert.Equal(t, "", buffer.String())
+
+	buffer.Reset()
+	printer.printTable([]interface{}{"COL1", " COL2"}, [][]interface{}{{"val1", 2}})
+	assert.Equal(t, "COL1,COL2\nval1,2\n", buffer.String())
+
+	buffer.Reset()
+	printer.printCollection(&struct{}{}, []interface{}{"COL1"}, [][]interface{}{{"val1"}, {"val2"}})
+	assert.Equal(t, "COL1\nval1\nval2\n", buffer.String())
+}
+
+func Test_serviceToCsv_printStructContent(t *testing.T) {
+
+	type TestedStruct struct {
+		Field1 string `json:"field_1"`
+		Field2 int32  `json:"field_2,omitempty"`
+		Field3 bool
+	}
+
+	buffer := new(bytes.Buffer)
+	NewServiceToCsv(buffer, ';').printStructContent(&TestedStruct{"value", 3, true})
+	assert.Equal(t, "field_1;field_2;Field3\nvalue;3;true\n", buffer.String())
+}
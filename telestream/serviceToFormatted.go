@@ -0,0 +1,345 @@
+package telestream
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// serviceToFormatted - output layer which selects columns or renders results with template before
+// passing them to next output
+type serviceToFormatted struct {
+	output   ServiceOutput
+	writer   io.Writer
+	columns  []string
+	template *template.Template
+}
+
+// Creates new formatted output, columns are json names of printed fields, template is go text/template
+// executed for each row or object
+func NewServiceToFormatted(output ServiceOutput, writer io.Writer, columns []string,
+	tmpl string) (*serviceToFormatted, error) {
+
+	printer := new(serviceToFormatted)
+	printer.output = output
+	printer.writer = writer
+
+	for _, column := range columns {
+		if column = strings.TrimSpace(column); column != "" {
+
+			printer.columns = append(printer.columns, column)
+		}
+	}
+
+	if tmpl != "" {
+
+		parsed, err := template.New("output").Parse(tmpl)
+		if err != nil {
+
+			return nil, errors.New("Cannot parse template: " + err.Error())
+		}
+		printer.template = parsed
+	}
+
+	return printer, nil
+}
+
+func (printer *serviceToFormatted) printError(fName string, err error) {
+
+	printer.output.printError(fName, err)
+}
+
+func (printer *serviceToFormatted) printInfo(info string) {
+
+	printer.output.printInfo(info)
+}
+
+// Print object selected columns as single row collection or render object with template
+func (printer *serviceToFormatted) printStructContent(j interface{}) {
+
+	if len(printer.columns) > 0 {
+
+		row, object, err := printer.selectColumns(j)
+		if err != nil {
+
+			printer.output.printError("Columns", err)
+			return
+		}
+
+		if printer.template != nil {
+
+			printer.executeTemplate(object)
+			return
+		}
+
+		printer.output.printCollection(object, printer.columnNames(), [][]interface{}{row})
+		return
+	}
+
+	if printer.template != nil {
+
+		printer.executeTemplate(j)
+		return
+	}
+
+	printer.output.printStructContent(j)
+}
+
+// Print table rows, columns are matched with table column names
+func (printer *serviceToFormatted) printTable(colNames []interface{}, rows [][]interface{}) {
+
+	objects := []map[string]interface{}{}
+
+	for _, row := range rows {
+
+		object := map[string]interface{}{}
+
+		for idx, val := range row {
+			if idx < len(colNames) {
+
+				object[strings.ToLower(strings.TrimSpace(fmt.Sprint(colNames[idx])))] = val
+			}
+		}
+
+		objects = append(objects, object)
+	}
+
+	if len(printer.columns) > 0 {
+
+		selectedRows := [][]interface{}{}
+
+		for _, object := range objects {
+
+			row, _, err := printer.selectColumns(object)
+			if err != nil {
+
+				printer.output.printError("Columns", err)
+				return
+			}
+
+			selectedRows = append(selectedRows, row)
+		}
+
+		colNames = printer.columnNames()
+		rows = selectedRows
+	}
+
+	if printer.template != nil {
+
+		for _, object := range objects {
+
+			printer.executeTemplate(object)
+		}
+		return
+	}
+
+	printer.output.printTable(colNames, rows)
+}
+
+// Print collection items with selected columns or render each item with template
+func (printer *serviceToFormatted) printCollection(j interface{}, colNames []interface{}, rows [][]interface{}) {
+
+	items, ok := collectionItems(j)
+	if !ok {
+
+		printer.output.printCollection(j, colNames, rows)
+		return
+	}
+
+	objects := []interface{}{}
+
+	for i := 0; i < items.Len(); i++ {
+
+		objects = append(objects, items.Index(i).Interface())
+	}
+
+	if len(printer.columns) > 0 {
+
+		selectedObjects := []interface{}{}
+		selectedRows := [][]interface{}{}
+
+		for _, object := range objects {
+
+			row, selectedObject, err := printer.selectColumns(object)
+			if err != nil {
+
+				printer.output.printError("Columns", err)
+				return
+			}
+
+			selectedRows = append(selectedRows, row)
+			selectedObjects = append(selectedObjects, selectedObject)
+		}
+
+		j = selectedObjects
+		colNames = printer.columnNames()
+		rows = selectedRows
+		objects = selectedObjects
+	}
+
+	if printer.template != nil {
+
+		for _, object := range objects {
+
+			printer.executeTemplate(object)
+		}
+		return
+	}
+
+	printer.output.printCollection(j, colNames, rows)
+}
+
+func (printer *serviceToFormatted) columnNames() []interface{} {
+
+	colNames := []interface{}{}
+
+	for _, column := range printer.columns {
+		colNames = append(colNames, strings.ToUpper(column))
+	}
+
+	return colNames
+}
+
+// Get values of selected columns as table row and as object with column names as keys
+func (printer *serviceToFormatted) selectColumns(j interface{}) ([]interface{}, map[string]interface{}, error) {
+
+	row := []interface{}{}
+	object := map[string]interface{}{}
+
+	for _, column := range printer.columns {
+
+		val, ok := fieldValue(j, column)
+		if !ok {
+
+			return nil, nil, errors.New("Unknown column: " + column)
+		}
+
+		row = append(row, val)
+		object[column] = val
+	}
+
+	return row, object, nil
+}
+
+// Execute template on object converted to its json representation, so json field names can be used
+func (printer *serviceToFormatted) executeTemplate(j interface{}) {
+
+	data, err := toJsonValue(j)
+	if err != nil {
+
+		printer.output.printError("Template", err)
+		return
+	}
+
+	buffer := new(bytes.Buffer)
+
+	if err = printer.template.Execute(buffer, data); err != nil {
+
+		printer.output.printError("Template", err)
+		return
+	}
+
+	if !bytes.HasSuffix(buffer.Bytes(), []byte("\n")) {
+		buffer.WriteString("\n")
+	}
+
+	printer.writer.Write(buffer.Bytes())
+}
+
+// Convert value to generic json value (maps, slices, json numbers, strings and bools)
+func toJsonValue(j interface{}) (interface{}, error) {
+
+	encoded, err := json.Marshal(j)
+	if err != nil {
+
+		return nil, err
+	}
+
+	var data interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+
+	if err = decoder.Decode(&data); err != nil {
+
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// Get collection items - the first slice of structures in collection structure
+func collectionItems(j interface{}) (reflect.Value, bool) {
+
+	e := reflect.ValueOf(j)
+	for e.Kind() == reflect.Ptr || e.Kind() == reflect.Interface {
+
+		if e.IsNil() {
+			return e, false
+		}
+		e = e.Elem()
+	}
+
+	if e.Kind() == reflect.Slice {
+
+		return e, true
+	}
+
+	if e.Kind() != reflect.Struct {
+
+		return e, false
+	}
+
+	for i := 0; i < e.NumField(); i++ {
+
+		field := e.Field(i)
+
+		if field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Struct {
+
+			return field, true
+		}
+	}
+
+	return e, false
+}
+
+// Get value of structure field given by its json name or value of map entry given by key
+func fieldValue(j interface{}, name string) (interface{}, bool) {
+
+	e := reflect.ValueOf(j)
+	for e.Kind() == reflect.Ptr || e.Kind() == reflect.Interface {
+
+		if e.IsNil() {
+			return nil, false
+		}
+		e = e.Elem()
+	}
+
+	switch e.Kind() {
+
+	case reflect.Struct:
+		for i := 0; i < e.NumField(); i++ {
+
+			if jsonFieldName(e.Type().Field(i)) == name {
+
+				return e.Field(i).Interface(), true
+			}
+		}
+
+	case reflect.Map:
+		if e.Type().Key().Kind() == reflect.String {
+
+			if val := e.MapIndex(reflect.ValueOf(name).Convert(e.Type().Key())); val.IsValid() {
+
+				return val.Interface(), true
+			}
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,122 @@
+package telestream
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type formattedItem struct {
+	Id       string `json:"id,omitempty"`
+	Status   string `json:"status,omitempty"`
+	Duration int32  `json:"duration,omitempty"`
+	FileSize int64  `json:"file_size,omitempty"`
+}
+
+type formattedCollection struct {
+	Items []formattedItem `json:"items,omitempty"`
+	Total int32           `json:"total,omitempty"`
+}
+
+func Test_serviceToFormatted_columns(t *testing.T) {
+
+	collection := formattedCollection{[]formattedItem{{"1", "success", 10, 100}, {"2", "fail", 20, 200}}, 2}
+
+	var testVector = []struct {
+		name    string
+		columns []string
+		print   func(printer *serviceToFormatted)
+		output  string
+	}{
+		{"collection", []string{"status", " duration"}, func(printer *serviceToFormatted) {
+			printer.printCollection(&collection, []interface{}{"ID"}, [][]interface{}{{"1"}, {"2"}})
+		}, "STATUS,DURATION\nsuccess,10\nfail,20\n"},
+		{"struct", []string{"file_size", "id"}, func(printer *serviceToFormatted) {
+			printer.printStructContent(&collection.Items[1])
+		}, "FILE_SIZE,ID\n200,2\n"},
+		{"table", []string{"created_at"}, func(printer *serviceToFormatted) {
+			printer.printTable([]interface{}{"ID", " CREATED_AT"}, [][]interface{}{{"1", "today"}})
+		}, "CREATED_AT\ntoday\n"},
+		{"unknown column", []string{"unknown"}, func(printer *serviceToFormatted) {
+			printer.printCollection(&collection, nil, nil)
+		}, "COMMAND,ERROR\nColumns,Unknown column: unknown\n"},
+		{"no columns", []string{""}, func(printer *serviceToFormatted) {
+			printer.printCollection(&collection, []interface{}{"ID"}, [][]interface{}{{"1"}, {"2"}})
+		}, "ID\n1\n2\n"},
+	}
+
+	for _, testEl := range testVector {
+
+		t.Run(testEl.name, func(t *testing.T) {
+
+			buffer := new(bytes.Buffer)
+			printer, err := NewServiceToFormatted(newTestCsvPrinter(buffer), buffer, testEl.columns, "")
+			assert.Nil(t, err)
+
+			testEl.print(printer)
+			assert.Equal(t, testEl.output, buffer.String())
+		})
+	}
+}
+
+func Test_serviceToFormatted_jsonColumns(t *testing.T) {
+
+	collection := formattedCollection{[]formattedItem{{"1", "success", 10, 100}}, 1}
+
+	buffer := new(bytes.Buffer)
+	printer, err := NewServiceToFormatted(NewServiceToJson(buffer, ""), buffer, []string{"id", "file_size"}, "")
+	assert.Nil(t, err)
+
+	printer.printCollection(&collection, nil, nil)
+	assert.JSONEq(t, `[{"id": "1", "file_size": 100}]`, buffer.String())
+}
+
+func Test_serviceToFormatted_template(t *testing.T) {
+
+	collection := formattedCollection{[]formattedItem{{"1", "success", 10, 123456789}, {"2", "fail", 20, 200}}, 2}
+
+	var testVector = []struct {
+		name     string
+		columns  []string
+		template string
+		print    func(printer *serviceToFormatted)
+		output   string
+	}{
+		{"collection", nil, "{{.id}} {{.status}} {{.file_size}}", func(printer *serviceToFormatted) {
+			printer.printCollection(&collection, nil, nil)
+		}, "1 success 123456789\n2 fail 200\n"},
+		{"struct", nil, "{{.status}}\n", func(printer *serviceToFormatted) {
+			printer.printStructContent(&collection.Items[0])
+		}, "success\n"},
+		{"table", nil, "{{.id}}-{{.created_at}}", func(printer *serviceToFormatted) {
+			printer.printTable([]interface{}{"ID", " CREATED_AT"}, [][]interface{}{{"1", "today"}})
+		}, "1-today\n"},
+		{"columns and template", []string{"status"}, "{{.status}} {{.id}}", func(printer *serviceToFormatted) {
+			printer.printCollection(&collection, nil, nil)
+		}, "success <no value>\nfail <no value>\n"},
+	}
+
+	for _, testEl := range testVector {
+
+		t.Run(testEl.name, func(t *testing.T) {
+
+			buffer := new(bytes.Buffer)
+			printer, err := NewServiceToFormatted(NewServiceToCsv(buffer, ','), buffer, testEl.columns, testEl.template)
+			assert.Nil(t, err)
+
+			testEl.print(printer)
+			assert.Equal(t, testEl.output, buffer.String())
+		})
+	}
+
+	buffer := new(bytes.Buffer)
+	printer, err := NewServiceToFormatted(newTestCsvPrinter(buffer), buffer, nil, "{{.id.value}}")
+	assert.Nil(t, err)
+
+	printer.printStructContent(&collection.Items[0])
+	assert.Contains(t, buffer.String(), "COMMAND,ERROR\nTemplate,")
+
+	_, err = NewServiceToFormatted(ServiceToStdOut, new(bytes.Buffer), nil, "{{.id")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,107 @@
+package telestream
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var ServiceToJson = NewServiceToJson(os.Stdout, "  ")
+
+type serviceToJson struct {
+	writer    io.Writer
+	errWriter io.Writer
+	indent    string
+}
+
+// Creates new json printer which writes every result as json document to given writer, errors are written
+// to stderr
+func NewServiceToJson(writer io.Writer, indent string) *serviceToJson {
+
+	printer := new(serviceToJson)
+	printer.writer = writer
+	printer.errWriter = os.Stderr
+	printer.indent = indent
+
+	return printer
+}
+
+func (printer *serviceToJson) encode(j interface{}) {
+
+	printer.encodeTo(printer.writer, j)
+}
+
+func (printer *serviceToJson) encodeTo(writer io.Writer, j interface{}) {
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", printer.indent)
+
+	if err := encoder.Encode(j); err != nil {
+
+		encoder.Encode(map[string]string{"error": "cannot encode output: " + err.Error()})
+	}
+}
+
+func (printer *serviceToJson) printError(fName string, err error) {
+
+	if apiErr, ok := err.(*ApiError); ok {
+
+		printer.encodeTo(printer.errWriter, struct {
+			Command     string       `json:"command"`
+			Status      string       `json:"status"`
+			StatusCode  int          `json:"status_code,omitempty"`
+			Error       string       `json:"error"`
+			FieldErrors []FieldError `json:"field_errors,omitempty"`
+			RequestId   string       `json:"request_id,omitempty"`
+		}{fName, apiErr.Status, apiErr.StatusCode, apiErr.Message, apiErr.FieldErrors, apiErr.RequestId})
+		return
+	}
+
+	errorMsg := ""
+	if err != nil {
+		errorMsg = err.Error()
+	}
+
+	printer.encodeTo(printer.errWriter, map[string]string{"command": fName, "error": errorMsg})
+}
+
+func (printer *serviceToJson) printStructContent(j interface{}) {
+
+	printer.encode(j)
+}
+
+// Print informational message to stderr, so stdout holds single json document of command result
+func (printer *serviceToJson) printInfo(info string) {
+
+	printer.encodeTo(printer.errWriter, map[string]string{"info": info})
+}
+
+// Print table rows as list of objects, column names are used as keys
+func (printer *serviceToJson) printTable(colNames []interface{}, rows [][]interface{}) {
+
+	objects := []map[string]interface{}{}
+
+	for _, row := range rows {
+
+		object := map[string]interface{}{}
+
+		for idx, val := range row {
+			if idx < len(colNames) {
+
+				object[strings.ToLower(strings.TrimSpace(fmt.Sprint(colNames[idx])))] = val
+			}
+		}
+
+		objects = append(objects, object)
+	}
+
+	printer.encode(objects)
+}
+
+// Print whole collection returned by service, table columns are skipped
+func (printer *serviceToJson) printCollection(j interface{}, colNames []interface{}, rows [][]interface{}) {
+
+	printer.encode(j)
+}
@@ -0,0 +1,144 @@
+package telestream
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_serviceToJson_prints(t *testing.T) {
+
+	var testVector = []struct {
+		name   string
+		print  func(printer *serviceToJson)
+		output interface{}
+	}{
+		{"error", func(printer *serviceToJson) { printer.printError("name", errors.New("some error")) },
+			map[string]interface{}{"command": "name", "error": "some error"}},
+		{"api error", func(printer *serviceToJson) {
+			printer.printError("name", &ApiError{Status: "422 Unprocessable Entity", StatusCode: 422,
+				Message: "Validation failed", FieldErrors: []FieldError{{"width", "must be positive"}},
+				RequestId: "req-1"})
+		}, map[string]interface{}{"command": "name", "status": "422 Unprocessable Entity", "status_code": 422.0,
+			"error": "Validation failed", "request_id": "req-1",
+			"field_errors": []interface{}{map[string]interface{}{"field": "width", "message": "must be positive"}}}},
+		{"nil error", func(printer *serviceToJson) { printer.printError("", nil) },
+			map[string]interface{}{"command": "", "error": ""}},
+		{"info", func(printer *serviceToJson) { printer.printInfo("info") },
+			map[string]interface{}{"info": "info"}},
+		{"empty table", func(printer *serviceToJson) { printer.printTable(nil, nil) },
+			[]interface{}{}},
+		{"table", func(printer *serviceToJson) {
+			printer.printTable([]interface{}{"COL1", " COL2"}, [][]interface{}{{"val1", "val2"}})
+		}, []interface{}{map[string]interface{}{"col1": "val1", "col2": "val2"}}},
+		{"table without columns", func(printer *serviceToJson) {
+			printer.printTable(nil, [][]interface{}{{"val1", "val2"}})
+		}, []interface{}{map[string]interface{}{}}},
+	}
+
+	for _, testEl := range testVector {
+
+		t.Run(testEl.name, func(t *testing.T) {
+
+			buffer := new(bytes.Buffer)
+			printer := NewServiceToJson(buffer, "")
+			printer.errWriter = buffer
+
+			testEl.print(printer)
+
+			var output interface{}
+			assert.Nil(t, json.Unmarshal(buffer.Bytes(), &output))
+			assert.Equal(t, testEl.output, output)
+		})
+	}
+}
+
+// marshalFailure - value which cannot be encoded as json, error message holds characters escaped in json
+type marshalFailure struct{}
+
+func (value marshalFailure) MarshalJSON() ([]byte, error) {
+
+	return nil, errors.New("invalid \"value\" \\")
+}
+
+func Test_serviceToJson_infoAndEncodeError(t *testing.T) {
+
+	buffer := new(bytes.Buffer)
+	errBuffer := new(bytes.Buffer)
+	printer := NewServiceToJson(buffer, "")
+	printer.errWriter = errBuffer
+
+	// info does not break json document of result printed to stdout
+	printer.printStructContent(map[string]string{"id": "video"})
+	printer.printInfo("Encoding ids: a, b")
+
+	var output interface{}
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), &output))
+	assert.Equal(t, map[string]interface{}{"id": "video"}, output)
+	assert.Nil(t, json.Unmarshal(errBuffer.Bytes(), &output))
+	assert.Equal(t, map[string]interface{}{"info": "Encoding ids: a, b"}, output)
+
+	buffer.Reset()
+	printer.printStructContent(marshalFailure{})
+
+	var errorOutput map[string]string
+	assert.Nil(t, json.Unmarshal(buffer.Bytes(), &errorOutput))
+	assert.True(t, strings.HasPrefix(errorOutput["error"], "cannot encode output: "), errorOutput["error"])
+	assert.True(t, strings.HasSuffix(errorOutput["error"], "invalid \"value\" \\"), errorOutput["error"])
+}
+
+func Test_serviceToJson_printStructContent(t *testing.T) {
+
+	type TestedStruct struct {
+		Field1 string `json:"field_1"`
+		Field2 int32  `json:"field_2,omitempty"`
+		Field3 bool
+	}
+
+	type TestedCollection struct {
+		Items []TestedStruct `json:"items"`
+		Total int32          `json:"total"`
+	}
+
+	buffer := new(bytes.Buffer)
+	printer := NewServiceToJson(buffer, "  ")
+
+	printer.printStructContent(&TestedStruct{"value", 0, true})
+	assert.JSONEq(t, `{"field_1": "value", "Field3": true}`, buffer.String())
+
+	buffer.Reset()
+	collection := TestedCollection{[]TestedStruct{{"first", 1, false}, {"second", 2, true}}, 2}
+	printer.printCollection(&collection, []interface{}{"FIELD_1"}, [][]interface{}{{"first"}, {"second"}})
+	assert.JSONEq(t, `{"items": [{"field_1": "first", "field_2": 1, "Field3": false},
+		{"field_1": "second", "field_2": 2, "Field3": true}], "total": 2}`, buffer.String())
+}
+
+func Test_GetServiceOutput(t *testing.T) {
+
+	output, err := GetServiceOutput("")
+	assert.Nil(t, err)
+	assert.Equal(t, ServiceToStdOut, output)
+
+	output, err = GetServiceOutput("table")
+	assert.Nil(t, err)
+	assert.Equal(t, ServiceToStdOut, output)
+
+	output, err = GetServiceOutput("json")
+	assert.Nil(t, err)
+	assert.Equal(t, ServiceToJson, output)
+
+	output, err = GetServiceOutput("yaml")
+	assert.Nil(t, err)
+	assert.Equal(t, ServiceToYaml, output)
+
+	output, err = GetServiceOutput("csv")
+	assert.Nil(t, err)
+	assert.Equal(t, ServiceToCsv, output)
+
+	_, err = GetServiceOutput("xml")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,285 @@
+package telestream
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// queryStep - single step of query path: object field, list index or list projection ([*])
+type queryStep struct {
+	field    string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+// serviceToQueried - output layer which applies query expression on service result before passing it to
+// next output
+type serviceToQueried struct {
+	output ServiceOutput
+	steps  []queryStep
+}
+
+// Creates new queried output, query is a JMESPath like path (e.g. status, videos[0].id, videos[*].id)
+func NewServiceToQueried(output ServiceOutput, query string) (*serviceToQueried, error) {
+
+	steps, err := parseQuery(query)
+	if err != nil {
+
+		return nil, err
+	}
+
+	printer := new(serviceToQueried)
+	printer.output = output
+	printer.steps = steps
+
+	return printer, nil
+}
+
+func (printer *serviceToQueried) printError(fName string, err error) {
+
+	printer.output.printError(fName, err)
+}
+
+func (printer *serviceToQueried) printInfo(info string) {
+
+	printer.output.printInfo(info)
+}
+
+func (printer *serviceToQueried) printStructContent(j interface{}) {
+
+	printer.printQueried(j)
+}
+
+// Query table rows, rows are converted to objects with column names as keys
+func (printer *serviceToQueried) printTable(colNames []interface{}, rows [][]interface{}) {
+
+	objects := []map[string]interface{}{}
+
+	for _, row := range rows {
+
+		object := map[string]interface{}{}
+
+		for idx, val := range row {
+			if idx < len(colNames) {
+
+				object[strings.ToLower(strings.TrimSpace(fmt.Sprint(colNames[idx])))] = val
+			}
+		}
+
+		objects = append(objects, object)
+	}
+
+	printer.printQueried(objects)
+}
+
+func (printer *serviceToQueried) printCollection(j interface{}, colNames []interface{}, rows [][]interface{}) {
+
+	printer.printQueried(j)
+}
+
+// Apply query on json representation of given value and print result - list of objects is printed as
+// collection, any other value is printed as object content
+func (printer *serviceToQueried) printQueried(j interface{}) {
+
+	data, err := toJsonValue(j)
+	if err != nil {
+
+		printer.output.printError("Query", err)
+		return
+	}
+
+	result := evaluateQuery(printer.steps, data)
+
+	if list, ok := result.([]interface{}); ok && len(list) > 0 {
+
+		keys := map[string]bool{}
+		isObjectsList := true
+
+		for _, el := range list {
+
+			object, isObject := el.(map[string]interface{})
+			if !isObject {
+
+				isObjectsList = false
+				break
+			}
+
+			for key := range object {
+				keys[key] = true
+			}
+		}
+
+		if isObjectsList {
+
+			names := []string{}
+			for key := range keys {
+				names = append(names, key)
+			}
+			sort.Strings(names)
+
+			colNames := []interface{}{}
+			for _, name := range names {
+				colNames = append(colNames, strings.ToUpper(name))
+			}
+
+			rows := [][]interface{}{}
+			for _, el := range list {
+
+				row := []interface{}{}
+				for _, name := range names {
+					row = append(row, el.(map[string]interface{})[name])
+				}
+				rows = append(rows, row)
+			}
+
+			printer.output.printCollection(list, colNames, rows)
+			return
+		}
+	}
+
+	printer.output.printStructContent(result)
+}
+
+// Parse query path, leading $ (JSONPath root) is optional
+func parseQuery(query string) ([]queryStep, error) {
+
+	steps := []queryStep{}
+	path := strings.TrimSpace(query)
+	path = strings.TrimPrefix(path, "$")
+	path = strings.TrimPrefix(path, ".")
+
+	for len(path) > 0 {
+
+		switch {
+
+		case path[0] == '[':
+			end := strings.Index(path, "]")
+			if end < 0 {
+
+				return nil, errors.New("Invalid query: missing ] in " + query)
+			}
+
+			selector := strings.TrimSpace(path[1:end])
+			if selector == "*" {
+
+				steps = append(steps, queryStep{wildcard: true})
+			} else if index, err := strconv.Atoi(selector); err == nil {
+
+				steps = append(steps, queryStep{index: index, isIndex: true})
+			} else {
+
+				return nil, errors.New("Invalid query: wrong index " + selector + " in " + query)
+			}
+
+			path = path[end+1:]
+
+		case path[0] == '.':
+			path = path[1:]
+			if len(path) == 0 || path[0] == '.' || path[0] == '[' {
+
+				return nil, errors.New("Invalid query: missing field name in " + query)
+			}
+
+		default:
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+
+			field := strings.TrimSpace(path[:end])
+			if field == "*" {
+
+				steps = append(steps, queryStep{wildcard: true})
+			} else {
+
+				steps = append(steps, queryStep{field: field})
+			}
+
+			path = path[end:]
+		}
+	}
+
+	if len(steps) == 0 {
+
+		return nil, errors.New("Invalid query: empty expression")
+	}
+
+	return steps, nil
+}
+
+// Evaluate query steps on json value, projection ([*]) applies rest of the steps on each list element and
+// skips null results
+func evaluateQuery(steps []queryStep, data interface{}) interface{} {
+
+	if len(steps) == 0 || data == nil {
+
+		return data
+	}
+
+	step := steps[0]
+
+	switch {
+
+	case step.wildcard:
+		elements := []interface{}{}
+
+		switch value := data.(type) {
+
+		case []interface{}:
+			elements = value
+
+		case map[string]interface{}:
+			keys := []string{}
+			for key := range value {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+
+			for _, key := range keys {
+				elements = append(elements, value[key])
+			}
+
+		default:
+			return nil
+		}
+
+		results := []interface{}{}
+		for _, element := range elements {
+
+			if result := evaluateQuery(steps[1:], element); result != nil {
+				results = append(results, result)
+			}
+		}
+
+		return results
+
+	case step.isIndex:
+		list, ok := data.([]interface{})
+		if !ok {
+			return nil
+		}
+
+		index := step.index
+		if index < 0 {
+			index += len(list)
+		}
+
+		if index < 0 || index >= len(list) {
+			return nil
+		}
+
+		return evaluateQuery(steps[1:], list[index])
+
+	default:
+		object, ok := data.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		return evaluateQuery(steps[1:], object[step.field])
+	}
+}
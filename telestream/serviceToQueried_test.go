@@ -0,0 +1,126 @@
+package telestream
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseQuery(t *testing.T) {
+
+	var testVector = []struct {
+		query string
+		steps []queryStep
+		valid bool
+	}{
+		{"status", []queryStep{{field: "status"}}, true},
+		{"$.status", []queryStep{{field: "status"}}, true},
+		{".videos[0].id", []queryStep{{field: "videos"}, {index: 0, isIndex: true}, {field: "id"}}, true},
+		{"videos[*].id", []queryStep{{field: "videos"}, {wildcard: true}, {field: "id"}}, true},
+		{"videos.*", []queryStep{{field: "videos"}, {wildcard: true}}, true},
+		{"videos[-1]", []queryStep{{field: "videos"}, {index: -1, isIndex: true}}, true},
+		{"", nil, false},
+		{"videos[0", nil, false},
+		{"videos[a]", nil, false},
+		{"videos..id", nil, false},
+		{"videos.", nil, false},
+	}
+
+	for _, testEl := range testVector {
+
+		t.Run(testEl.query, func(t *testing.T) {
+
+			steps, err := parseQuery(testEl.query)
+			assert.Equal(t, testEl.valid, err == nil)
+			if testEl.valid {
+				assert.Equal(t, testEl.steps, steps)
+			}
+		})
+	}
+}
+
+func Test_evaluateQuery(t *testing.T) {
+
+	var data interface{}
+	json.Unmarshal([]byte(`{"status": "success", "videos": [{"id": "1", "tags": ["a"]}, {"id": "2"}, {"name": "x"}],
+		"meta": {"b": 2, "a": 1}}`), &data)
+
+	var testVector = []struct {
+		query  string
+		result interface{}
+	}{
+		{"status", "success"},
+		{"unknown", nil},
+		{"status.id", nil},
+		{"videos[1].id", "2"},
+		{"videos[-1].name", "x"},
+		{"videos[5]", nil},
+		{"videos[*].id", []interface{}{"1", "2"}},
+		{"videos[*].tags[0]", []interface{}{"a"}},
+		{"meta.*", []interface{}{float64(1), float64(2)}},
+		{"status[*]", nil},
+	}
+
+	for _, testEl := range testVector {
+
+		t.Run(testEl.query, func(t *testing.T) {
+
+			steps, err := parseQuery(testEl.query)
+			assert.Nil(t, err)
+			assert.Equal(t, testEl.result, evaluateQuery(steps, data))
+		})
+	}
+}
+
+func Test_serviceToQueried_prints(t *testing.T) {
+
+	collection := formattedCollection{[]formattedItem{{"1", "success", 10, 100}, {"2", "fail", 20, 200}}, 2}
+
+	var testVector = []struct {
+		name   string
+		query  string
+		print  func(printer *serviceToQueried)
+		output string
+	}{
+		{"struct field", "status", func(printer *serviceToQueried) {
+			printer.printStructContent(&collection.Items[0])
+		}, "success\n"},
+		{"collection projection", "items[*].id", func(printer *serviceToQueried) {
+			printer.printCollection(&collection, nil, nil)
+		}, "1\n2\n"},
+		{"collection objects", "items", func(printer *serviceToQueried) {
+			printer.printCollection(&collection, nil, nil)
+		}, "DURATION,FILE_SIZE,ID,STATUS\n10,100,1,success\n20,200,2,fail\n"},
+		{"table", "[0].created_at", func(printer *serviceToQueried) {
+			printer.printTable([]interface{}{"ID", " CREATED_AT"}, [][]interface{}{{"1", "today"}})
+		}, "today\n"},
+		{"missing field", "unknown", func(printer *serviceToQueried) {
+			printer.printStructContent(&collection.Items[0])
+		}, "null\n"},
+	}
+
+	for _, testEl := range testVector {
+
+		t.Run(testEl.name, func(t *testing.T) {
+
+			buffer := new(bytes.Buffer)
+			printer, err := NewServiceToQueried(NewServiceToCsv(buffer, ','), testEl.query)
+			assert.Nil(t, err)
+
+			testEl.print(printer)
+			assert.Equal(t, testEl.output, buffer.String())
+		})
+	}
+
+	buffer := new(bytes.Buffer)
+	printer, err := NewServiceToQueried(NewServiceToJson(buffer, ""), "total")
+	assert.Nil(t, err)
+
+	printer.printCollection(&collection, nil, nil)
+	assert.Equal(t, "2\n", buffer.String())
+
+	_, err = NewServiceToQueried(ServiceToStdOut, "items[")
+	assert.NotNil(t, err)
+}
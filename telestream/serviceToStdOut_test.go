@@ -1,8 +1,11 @@
 package telestream
 
 import (
+	"bytes"
 	"errors"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func Test_serviceToStdOut_prints(t *testing.T) {
@@ -20,6 +23,22 @@ func Test_serviceToStdOut_prints(t *testing.T) {
 	ServiceToStdOut.printTable([]interface{}{"col1", "col2"}, [][]interface{}{{}})
 }
 
+func Test_serviceToStdOut_printError(t *testing.T) {
+
+	buffer := new(bytes.Buffer)
+	printer := NewServiceToStdOut(ServiceToStdOut.tableStyle)
+	printer.errWriter = buffer
+
+	printer.printError("DescribeVideo", errors.New("some error"))
+	assert.Equal(t, "DescribeVideo: some error\n", buffer.String())
+
+	buffer.Reset()
+	printer.printError("CreateVideo", &ApiError{Status: "422 Unprocessable Entity", Message: "Validation failed",
+		FieldErrors: []FieldError{{"source_url", "is invalid"}, {"", "profiles are missing"}}, RequestId: "req-1"})
+	assert.Equal(t, "CreateVideo: 422 Unprocessable Entity: Validation failed\n  source_url: is invalid\n"+
+		"  profiles are missing\nRequest ID: req-1\n", buffer.String())
+}
+
 func Test_serviceToStdOut_printStructContent(t *testing.T) {
 
 	type TestedStruct1 struct {
@@ -55,6 +74,10 @@ func Test_serviceToStdOut_printStructContent(t *testing.T) {
 		{&TestedStruct3{}},
 		{&TestedStruct4{}},
 		{&TestedStruct5{}},
+		{"value"},
+		{nil},
+		{[]interface{}{"value1", nil}},
+		{map[string]interface{}{"field_2": 2, "field_1": "value"}},
 	}
 
 	for _, testEl := range testVector {
@@ -2,9 +2,8 @@ package telestream
 
 import (
 	"fmt"
+	"io"
 	"os"
-	"reflect"
-	"strings"
 
 	"github.com/jedib0t/go-pretty/table"
 )
@@ -13,47 +12,73 @@ var ServiceToStdOut = NewServiceToStdOut(table.StyleLight)
 
 type serviceToStdOut struct {
 	tableStyle table.Style
+	errWriter  io.Writer
 }
 
 func NewServiceToStdOut(style table.Style) *serviceToStdOut {
 
 	printer := new(serviceToStdOut)
 	printer.tableStyle = style
+	printer.errWriter = os.Stderr
 
 	return printer
 }
 
 func (printer *serviceToStdOut) printError(fName string, err error) {
 
-	if err != nil {
-		fmt.Println(fName + err.Error())
-	} else {
-		fmt.Println(fName)
+	prefix := ""
+	if fName != "" {
+		prefix = fName + ": "
 	}
-}
 
-func (printer *serviceToStdOut) printStructContent(j interface{}) {
+	apiErr, ok := err.(*ApiError)
+	if !ok {
 
-	fmt.Println()
-	e := reflect.ValueOf(j).Elem()
+		if err != nil {
+			fmt.Fprintln(printer.errWriter, prefix+err.Error())
+		} else {
+			fmt.Fprintln(printer.errWriter, fName)
+		}
+		return
+	}
+
+	// API error is printed with every field error and request id in separate lines
+	if apiErr.Message != "" {
+		fmt.Fprintln(printer.errWriter, prefix+apiErr.Status+": "+apiErr.Message)
+	} else {
+		fmt.Fprintln(printer.errWriter, prefix+apiErr.Status)
+	}
+
+	for _, fieldErr := range apiErr.FieldErrors {
+		fmt.Fprintln(printer.errWriter, "  "+fieldErr.String())
+	}
 
-	for i := 0; i < e.NumField(); i++ {
+	if apiErr.RequestId != "" {
+		fmt.Fprintln(printer.errWriter, "Request ID: "+apiErr.RequestId)
+	}
+}
 
-		varField := e.Type().Field(i)
-		varName := varField.Name
-		varValue := e.Field(i).Interface()
+func (printer *serviceToStdOut) printStructContent(j interface{}) {
 
-		if jsonTag := varField.Tag.Get("json"); jsonTag != "" && jsonTag != "-" {
-			if commaIdx := strings.Index(jsonTag, ","); commaIdx > 0 {
+	names, values, ok := structContent(j)
+	if !ok {
 
-				varName = jsonTag[:commaIdx]
+		// not a structure (e.g. query result) - print each value in separate line
+		for _, value := range listContent(j) {
+			if value == nil {
+				fmt.Println("null")
 			} else {
-
-				varName = jsonTag
+				fmt.Println(value)
 			}
 		}
+		return
+	}
 
-		fmt.Printf("%v: %v\n", varName, varValue)
+	fmt.Println()
+
+	for idx, varName := range names {
+
+		fmt.Printf("%v: %v\n", varName, values[idx])
 	}
 	fmt.Println()
 }
@@ -77,3 +102,9 @@ func (printer *serviceToStdOut) printTable(colNames []interface{}, rows [][]inte
 
 	tableWriter.Render()
 }
+
+// Print collection returned by service as table
+func (printer *serviceToStdOut) printCollection(j interface{}, colNames []interface{}, rows [][]interface{}) {
+
+	printer.printTable(colNames, rows)
+}
@@ -0,0 +1,197 @@
+package telestream
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+var ServiceToYaml = NewServiceToYaml(os.Stdout)
+
+type serviceToYaml struct {
+	writer    io.Writer
+	errWriter io.Writer
+}
+
+// Creates new yaml printer which writes every result as yaml document to given writer, errors are written
+// to stderr
+func NewServiceToYaml(writer io.Writer) *serviceToYaml {
+
+	printer := new(serviceToYaml)
+	printer.writer = writer
+	printer.errWriter = os.Stderr
+
+	return printer
+}
+
+func (printer *serviceToYaml) printDocument(document interface{}) {
+
+	printer.printDocumentTo(printer.writer, document)
+}
+
+// Marshal value as yaml document which starts with --- separator, so printed results form yaml stream
+func (printer *serviceToYaml) printDocumentTo(writer io.Writer, document interface{}) {
+
+	content, err := yaml.Marshal(document)
+	if err != nil {
+
+		fmt.Fprintln(printer.errWriter, "Cannot print yaml: "+err.Error())
+		return
+	}
+
+	fmt.Fprint(writer, "---\n"+string(content))
+}
+
+func (printer *serviceToYaml) printError(fName string, err error) {
+
+	if apiErr, ok := err.(*ApiError); ok {
+
+		document := yaml.MapSlice{{Key: "command", Value: fName}, {Key: "status", Value: apiErr.Status},
+			{Key: "error", Value: apiErr.Message}}
+
+		if len(apiErr.FieldErrors) > 0 {
+
+			fieldErrors := []yaml.MapSlice{}
+			for _, fieldErr := range apiErr.FieldErrors {
+				fieldErrors = append(fieldErrors, yaml.MapSlice{{Key: "field", Value: fieldErr.Field},
+					{Key: "message", Value: fieldErr.Message}})
+			}
+			document = append(document, yaml.MapItem{Key: "field_errors", Value: fieldErrors})
+		}
+
+		if apiErr.RequestId != "" {
+			document = append(document, yaml.MapItem{Key: "request_id", Value: apiErr.RequestId})
+		}
+
+		printer.printDocumentTo(printer.errWriter, document)
+		return
+	}
+
+	errorMsg := ""
+	if err != nil {
+		errorMsg = err.Error()
+	}
+
+	printer.printDocumentTo(printer.errWriter, yaml.MapSlice{{Key: "command", Value: fName},
+		{Key: "error", Value: errorMsg}})
+}
+
+// Walk structure fields the same way as table printer does and print them as yaml mapping
+func (printer *serviceToYaml) printStructContent(j interface{}) {
+
+	printer.printDocument(yamlValue(reflect.ValueOf(j)))
+}
+
+// Print informational message to stderr, so stdout holds only yaml documents of command results
+func (printer *serviceToYaml) printInfo(info string) {
+
+	printer.printDocumentTo(printer.errWriter, yaml.MapSlice{{Key: "info", Value: info}})
+}
+
+// Print table rows as list of mappings, column names are used as keys
+func (printer *serviceToYaml) printTable(colNames []interface{}, rows [][]interface{}) {
+
+	document := []yaml.MapSlice{}
+
+	for _, row := range rows {
+
+		mapping := yaml.MapSlice{}
+
+		for idx, val := range row {
+			if idx < len(colNames) {
+
+				name := strings.ToLower(strings.TrimSpace(fmt.Sprint(colNames[idx])))
+				mapping = append(mapping, yaml.MapItem{Key: name, Value: fmt.Sprint(val)})
+			}
+		}
+
+		document = append(document, mapping)
+	}
+
+	printer.printDocument(document)
+}
+
+// Print whole collection returned by service, table columns are skipped
+func (printer *serviceToYaml) printCollection(j interface{}, colNames []interface{}, rows [][]interface{}) {
+
+	printer.printStructContent(j)
+}
+
+// Convert value to value marshaled as yaml: structures are mappings of exported fields named by json names
+// in order of fields, maps are mappings sorted by keys and json numbers are numbers
+func yamlValue(value reflect.Value) interface{} {
+
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+
+	case reflect.Struct:
+		if value.Type() == reflect.TypeOf(time.Time{}) {
+			return value.Interface()
+		}
+
+		mapping := yaml.MapSlice{}
+		for i := 0; i < value.NumField(); i++ {
+
+			if value.Type().Field(i).PkgPath != "" {
+				continue
+			}
+
+			mapping = append(mapping, yaml.MapItem{Key: jsonFieldName(value.Type().Field(i)),
+				Value: yamlValue(value.Field(i))})
+		}
+
+		return mapping
+
+	case reflect.Map:
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j]) })
+
+		mapping := yaml.MapSlice{}
+		for _, key := range keys {
+			mapping = append(mapping, yaml.MapItem{Key: fmt.Sprint(key), Value: yamlValue(value.MapIndex(key))})
+		}
+
+		return mapping
+
+	case reflect.Slice, reflect.Array:
+		list := []interface{}{}
+		for i := 0; i < value.Len(); i++ {
+			list = append(list, yamlValue(value.Index(i)))
+		}
+
+		return list
+
+	case reflect.String:
+		if number, ok := value.Interface().(json.Number); ok {
+
+			if intValue, err := number.Int64(); err == nil {
+				return intValue
+			}
+
+			if floatValue, err := number.Float64(); err == nil {
+				return floatValue
+			}
+		}
+
+		return value.String()
+
+	case reflect.Invalid:
+		return nil
+	}
+
+	return value.Interface()
+}
@@ -0,0 +1,98 @@
+package telestream
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_serviceToYaml_prints(t *testing.T) {
+
+	buffer := new(bytes.Buffer)
+	printer := NewServiceToYaml(buffer)
+	printer.errWriter = buffer
+
+	printer.printError("name", errors.New("some error: 404"))
+	assert.Equal(t, "---\ncommand: name\nerror: 'some error: 404'\n", buffer.String())
+
+	buffer.Reset()
+	printer.printError("name", &ApiError{Status: "422 Unprocessable Entity", Message: "Validation failed",
+		FieldErrors: []FieldError{{"width", "must be positive"}}, RequestId: "req-1"})
+	assert.Equal(t, "---\ncommand: name\nstatus: 422 Unprocessable Entity\nerror: Validation failed\n"+
+		"field_errors:\n- field: width\n  message: must be positive\nrequest_id: req-1\n", buffer.String())
+
+	// info is printed to stderr
+	buffer.Reset()
+	errBuffer := new(bytes.Buffer)
+	printer.errWriter = errBuffer
+	printer.printInfo("info")
+	assert.Equal(t, "", buffer.String())
+	assert.Equal(t, "---\ninfo: info\n", errBuffer.String())
+
+	buffer.Reset()
+	printer.printTable(nil, nil)
+	assert.Equal(t, "---\n[]\n", buffer.String())
+
+	buffer.Reset()
+	printer.printTable([]interface{}{"COL1", " COL2"}, [][]interface{}{{"val1", 2}, {}})
+	assert.Equal(t, "---\n- col1: val1\n  col2: \"2\"\n- {}\n", buffer.String())
+}
+
+func Test_serviceToYaml_printStructContent(t *testing.T) {
+
+	type TestedStruct1 struct {
+		Field1 string `json:"field_1"`
+		Field2 int32  `json:"field_2,omitempty"`
+		Field3 bool
+	}
+
+	type TestedStruct2 struct {
+		Items   []TestedStruct1   `json:"items"`
+		Names   []string          `json:"names"`
+		Empty   []string          `json:"empty"`
+		Labels  map[string]string `json:"labels"`
+		Nested  *TestedStruct1    `json:"nested"`
+		Pointer *interface{}      `json:"pointer"`
+	}
+
+	type TestedStruct3 struct {
+		Count     json.Number `json:"count"`
+		Rate      json.Number `json:"rate"`
+		CreatedAt time.Time   `json:"created_at"`
+		Text      string      `json:"text"`
+	}
+
+	var testVector = []struct {
+		name      string
+		structure interface{}
+		output    string
+	}{
+		{"flat", &TestedStruct1{"value", 0, true}, "---\nfield_1: value\nfield_2: 0\nField3: true\n"},
+		{"quoted", &TestedStruct1{"", 1, false}, "---\nfield_1: \"\"\nfield_2: 1\nField3: false\n"},
+		{"nested", &TestedStruct2{Items: []TestedStruct1{{"a", 1, true}}, Names: []string{"x", "true"},
+			Labels: map[string]string{"b": "2", "a": "1"}, Nested: &TestedStruct1{"n", 2, false}},
+			"---\nitems:\n- field_1: a\n  field_2: 1\n  Field3: true\nnames:\n- x\n- \"true\"\nempty: []\n" +
+				"labels:\n  a: \"1\"\n  b: \"2\"\nnested:\n  field_1: \"n\"\n  field_2: 2\n  Field3: false\npointer: null\n"},
+		{"numbers, time and text", &TestedStruct3{Count: json.Number("10"), Rate: json.Number("29.97"),
+			CreatedAt: time.Date(2019, 6, 10, 12, 0, 0, 0, time.UTC), Text: "line 1\nline 2: # x"},
+			"---\ncount: 10\nrate: 29.97\ncreated_at: 2019-06-10T12:00:00Z\ntext: |-\n  line 1\n  line 2: # x\n"},
+	}
+
+	for _, testEl := range testVector {
+
+		t.Run(testEl.name, func(t *testing.T) {
+
+			buffer := new(bytes.Buffer)
+			NewServiceToYaml(buffer).printStructContent(testEl.structure)
+			assert.Equal(t, testEl.output, buffer.String())
+
+			buffer.Reset()
+			NewServiceToYaml(buffer).printCollection(testEl.structure, nil, nil)
+			assert.Equal(t, testEl.output, buffer.String())
+		})
+	}
+}
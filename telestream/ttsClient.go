@@ -2,7 +2,9 @@ package telestream
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"tcs-cli/cli"
 	"github.com/Telestream/telestream-cloud-go-sdk/tts"
 )
@@ -33,43 +35,86 @@ func NewTtsClient(xApiKey string, addHearderKey string, addHeaderVal string, out
 	return client
 }
 
+// Set base path of tts API (e.g. staging or local mock server), default SDK endpoint is used when empty
+func (client *TtsClient) SetEndpoint(endpoint string) error {
+
+	if endpoint == "" {
+
+		return nil
+	}
+
+	basePath, err := parseEndpoint(endpoint)
+	if err != nil {
+
+		return err
+	}
+
+	client.config.BasePath = basePath
+
+	return nil
+}
+
+// Add http headers sent with every tts API request, keys are canonicalized, so header given in other case
+// replaces earlier one instead of being sent twice
+func (client *TtsClient) SetHeaders(headers map[string]string) {
+
+	for key, val := range headers {
+
+		client.config.AddDefaultHeader(http.CanonicalHeaderKey(key), val)
+	}
+}
+
+// Set http client used for tts API requests
+func (client *TtsClient) SetHttpClient(httpClient *http.Client) {
+
+	client.config.HTTPClient = httpClient
+}
+
+// Get types of flags of tts commands, types of project and job fields are taken from API bodies, the fields
+// can also be set in -f file
+func (client *TtsClient) GetFlagTypes() cli.FlagTypes {
+
+	return addFileFlagTypes(mergeFlagTypes(commonFlagTypes, structToFlagTypes(&tts.Project{}),
+		structToFlagTypes(&tts.Job{})), &tts.Project{}, &tts.Job{})
+}
+
 // List all projets to output
-func (client *TtsClient) ListProjects() {
+func (client *TtsClient) ListProjects() error {
 
-	projectsCollection, _, err := client.client.TtsApi.Projects(client.ctx)
+	projectsCollection, resp, err := client.client.TtsApi.Projects(client.ctx)
 
 	colNames := []interface{}{"NAME", "ID", "CREATED_AT", "STATUS", "DESCRIPTION"}
 	rows := [][]interface{}{}
 
-	if nil == err {
+	if err != nil {
 
-		for _, project := range projectsCollection.Projects {
+		return newApiError("ListProjects", resp, err)
+	}
 
-			rows = append(rows, []interface{}{project.Name, project.Id, project.CreatedAt,
-				project.Status, project.Description})
-		}
+	for _, project := range projectsCollection.Projects {
 
-		client.output.printTable(colNames, rows)
+		rows = append(rows, []interface{}{project.Name, project.Id, project.CreatedAt,
+			project.Status, project.Description})
+	}
 
-	} else {
+	client.output.printCollection(&projectsCollection, colNames, rows)
 
-		client.output.printError("ListProjects", err)
-	}
+	return nil
 }
 
 // Print project description on output
-func (client *TtsClient) DescribeProject(argsMap cli.FlagMap) {
+func (client *TtsClient) DescribeProject(argsMap cli.FlagMap) error {
 
-	projectDesc, _, err := client.client.TtsApi.Project(client.ctx, *argsMap["project_id"].Value)
+	projectDesc, resp, err := client.client.TtsApi.Project(client.ctx, *argsMap["project_id"].Value)
 
-	if nil == err {
+	if err != nil {
 
-		client.output.printStructContent(&projectDesc)
+		return newApiError("DescribeProject", resp, err)
+	}
 
-	} else {
+	client.output.printStructContent(&projectDesc)
 
-		client.output.printError("DescribeProject", err)
-	}
+	return nil
 }
 
 // Get describe factory input attributes
@@ -81,21 +126,24 @@ func (client *TtsClient) GetDescribeProjectProperties() map[string]bool {
 }
 
 // Create new project
-func (client *TtsClient) CreateProject(argsMap cli.FlagMap) {
+func (client *TtsClient) CreateProject(argsMap cli.FlagMap) error {
 
 	newProject := tts.Project{}
-	propertiesToStruct(&newProject, argsMap)
-
-	projectDesc, _, err := client.client.TtsApi.CreateProject(client.ctx, newProject)
+	if err := readRequestBody(&newProject, &argsMap); err != nil {
 
-	if nil == err {
+		return newCommandError("CreateProject", err)
+	}
 
-		client.output.printStructContent(&projectDesc)
+	projectDesc, resp, err := client.client.TtsApi.CreateProject(client.ctx, newProject)
 
-	} else {
+	if err != nil {
 
-		client.output.printError("CreateProject", err)
+		return newApiError("CreateProject", resp, err)
 	}
+
+	client.output.printStructContent(&projectDesc)
+
+	return nil
 }
 
 // Get create project input attributes
@@ -114,49 +162,87 @@ func (client *TtsClient) GetCreateProjectProperties() map[string]bool {
 	flagMap["name"] = true
 	flagMap["description"] = true
 	flagMap["language"] = true
+	addFileOpt(flagMap)
 
 	return flagMap
 }
 
 // Delete project
-func (client *TtsClient) DeleteProject(argsMap cli.FlagMap) {
+func (client *TtsClient) DeleteProject(argsMap cli.FlagMap) error {
 
-	_, err := client.client.TtsApi.DeleteProject(client.ctx, *argsMap["project_id"].Value)
+	dryRun, err := getDryRunOpt(&argsMap)
+	if err != nil {
 
-	if nil == err {
+		return newCommandError("DeleteProject", err)
+	}
 
-		client.output.printInfo("Project: " + *argsMap["project_id"].Value + " removed.")
+	if dryRun {
+		defer useDryRunClient(&client.config.HTTPClient)()
+	}
 
-	} else {
+	yes, err := getYesOpt(&argsMap)
+	if err != nil {
 
-		client.output.printError("DescribeProject", err)
+		return newCommandError("DeleteProject", err)
 	}
+
+	if !yes && !dryRun {
+
+		err = confirmDelete("DeleteProject", "project", *argsMap["project_id"].Value,
+			func() (interface{}, *http.Response, error) {
+
+				project, resp, err := client.client.TtsApi.Project(client.ctx, *argsMap["project_id"].Value)
+				return &project, resp, err
+			})
+		if err != nil {
+
+			return err
+		}
+	}
+
+	resp, err := client.client.TtsApi.DeleteProject(client.ctx, *argsMap["project_id"].Value)
+
+	if err != nil {
+
+		if printDryRun(client.output, err) {
+			return nil
+		}
+
+		return newApiError("DeleteProject", resp, err)
+	}
+
+	client.output.printInfo("Project: " + *argsMap["project_id"].Value + " removed.")
+
+	return nil
 }
 
 // Get delete project input attributes
 func (client *TtsClient) GetDeleteProjectProperties() map[string]bool {
 
-	flagMap := map[string]bool{"project_id": true}
+	flagMap := map[string]bool{"project_id": true, "dry_run": false, "yes": false, "force": false}
 
 	return flagMap
 }
 
 // Update project and print its new body
-func (client *TtsClient) UpdateProject(argsMap cli.FlagMap) {
+func (client *TtsClient) UpdateProject(argsMap cli.FlagMap) error {
 
 	newProject := tts.Project{}
-	propertiesToStruct(&newProject, argsMap)
-
-	projectDesc, _, err := client.client.TtsApi.UpdateProject(client.ctx, newProject.Id, newProject)
+	if err := readRequestBody(&newProject, &argsMap); err != nil {
 
-	if nil == err {
+		return newCommandError("UpdateProject", err)
+	}
 
-		client.output.printStructContent(&projectDesc)
+	projectDesc, resp, err := client.client.TtsApi.UpdateProject(client.ctx, newProject.Id, newProject)
 
-	} else {
+	if err != nil {
 
-		client.output.printError("UpdateProject", err)
+		return newApiError("UpdateProject", resp, err)
 	}
+
+	client.output.printStructContent(&projectDesc)
+
+	return nil
 }
 
 // Get update project input attributes
@@ -173,39 +259,53 @@ func (client *TtsClient) GetUpdateProjectProperties() map[string]bool {
 	}
 
 	flagMap["id"] = true
+	addFileOpt(flagMap)
 
 	return flagMap
 }
 
 // List all projets to output
-func (client *TtsClient) ListJobs(argsMap cli.FlagMap) {
+func (client *TtsClient) ListJobs(argsMap cli.FlagMap) error {
 
 	opts, pageErr := getPageOpt(&argsMap)
 	if pageErr != nil {
 
-		client.output.printError("ListJobs", pageErr)
-		return
+		return newCommandError("ListJobs", pageErr)
+	}
+
+	all, limit, pageErr := getAllPagesOpt(&argsMap)
+	if pageErr != nil {
+
+		return newCommandError("ListJobs", pageErr)
 	}
 
-	jobsCollection, _, err := client.client.TtsApi.Jobs(client.ctx, *argsMap["project_id"].Value,
-		opts)
+	jobsCollection := tts.JobsCollection{}
+
+	err := fetchCollection(opts, all, limit, &jobsCollection, "Jobs",
+		func(opts map[string]interface{}) (interface{}, int, error) {
+
+			pageCollection, resp, err := client.client.TtsApi.Jobs(client.ctx, *argsMap["project_id"].Value,
+				opts)
+			return pageCollection, int(pageCollection.TotalCount), newApiError("ListJobs", resp, err)
+		})
+
+	if err != nil {
+
+		return err
+	}
 
 	// print all projects in table
 	colNames := []interface{}{"JOB_ID", "CREATED_AT", "STATUS", "STREAM_NAME", "DURATION", "CONFIDENCE"}
 	rows := [][]interface{}{}
 
-	if nil == err {
+	for _, job := range jobsCollection.Jobs {
 
-		for _, job := range jobsCollection.Jobs {
-
-			rows = append(rows, []interface{}{job.Id, job.CreatedAt, job.Status, job.Name, fmt.Sprint(job.Duration), fmt.Sprint(job.Confidence)})
-		}
+		rows = append(rows, []interface{}{job.Id, job.CreatedAt, job.Status, job.Name, fmt.Sprint(job.Duration), fmt.Sprint(job.Confidence)})
+	}
 
-		client.output.printTable(colNames, rows)
-	} else {
+	client.output.printCollection(&jobsCollection, colNames, rows)
 
-		client.output.printError("ListJobs", err)
-	}
+	return nil
 }
 
 // Get list jobs input attributes
@@ -218,21 +318,24 @@ func (client *TtsClient) GetListJobsProperties() map[string]bool {
 }
 
 // Create new job
-func (client *TtsClient) CreateJob(argsMap cli.FlagMap) {
+func (client *TtsClient) CreateJob(argsMap cli.FlagMap) error {
 
 	newJob := tts.Job{}
-	propertiesToStruct(&newJob, argsMap)
+	if err := readRequestBody(&newJob, &argsMap); err != nil {
 
-	jobDesc, _, err := client.client.TtsApi.CreateJob(client.ctx, newJob.ProjectId, newJob)
-
-	if nil == err {
+		return newCommandError("CreateJob", err)
+	}
 
-		client.output.printStructContent(&jobDesc)
+	jobDesc, resp, err := client.client.TtsApi.CreateJob(client.ctx, newJob.ProjectId, newJob)
 
-	} else {
+	if err != nil {
 
-		client.output.printError("CreateJob", err)
+		return newApiError("CreateJob", resp, err)
 	}
+
+	client.output.printStructContent(&jobDesc)
+
+	return nil
 }
 
 // Get create job input attributes
@@ -250,24 +353,25 @@ func (client *TtsClient) GetCreateJobProperties() map[string]bool {
 
 	flagMap["project_id"] = true
 	flagMap["source_url"] = true
+	addFileOpt(flagMap)
 
 	return flagMap
 }
 
 // Print job description on output
-func (client *TtsClient) DescribeJob(argsMap cli.FlagMap) {
+func (client *TtsClient) DescribeJob(argsMap cli.FlagMap) error {
 
-	jobDesc, _, err := client.client.TtsApi.Job(client.ctx, *argsMap["project_id"].Value,
+	jobDesc, resp, err := client.client.TtsApi.Job(client.ctx, *argsMap["project_id"].Value,
 		*argsMap["job_id"].Value)
 
-	if nil == err {
+	if err != nil {
 
-		client.output.printStructContent(&jobDesc)
+		return newApiError("DescribeJob", resp, err)
+	}
 
-	} else {
+	client.output.printStructContent(&jobDesc)
 
-		client.output.printError("DescribeJob", err)
-	}
+	return nil
 }
 
 // Get describe job input attributes
@@ -278,45 +382,114 @@ func (client *TtsClient) GetDescribeJobProperties() map[string]bool {
 	return flagMap
 }
 
+// Wait until job is finished (or timeout elapses), print job description on output
+func (client *TtsClient) WaitJob(argsMap cli.FlagMap) error {
+
+	timeout, err := getWaitTimeout(&argsMap)
+	if err != nil {
+
+		return newCommandError("WaitJob", err)
+	}
+
+	jobDesc := tts.Job{}
+
+	status, failed, err := waitForStatus(client.ctx, timeout, func(ctx context.Context) (string, error) {
+
+		var resp *http.Response
+		var err error
+		jobDesc, resp, err = client.client.TtsApi.Job(ctx, *argsMap["project_id"].Value, *argsMap["job_id"].Value)
+
+		return jobDesc.Status, newApiError("WaitJob", resp, err)
+	})
+
+	if err != nil {
+
+		if _, ok := err.(*CommandError); ok {
+
+			return err
+		}
+
+		return newCommandError("WaitJob", err)
+	}
+
+	client.output.printStructContent(&jobDesc)
+
+	if failed {
+
+		return newCommandError("WaitJob", errors.New("Job finished with status "+status))
+	}
+
+	return nil
+}
+
+// Get wait job input attributes
+func (client *TtsClient) GetWaitJobProperties() map[string]bool {
+
+	flagMap := map[string]bool{"project_id": true, "job_id": true, "timeout": false}
+
+	return flagMap
+}
+
 // Delete job
-func (client *TtsClient) DeleteJob(argsMap cli.FlagMap) {
+func (client *TtsClient) DeleteJob(argsMap cli.FlagMap) error {
 
-	_, err := client.client.TtsApi.DeleteJob(client.ctx, *argsMap["project_id"].Value,
-		*argsMap["job_id"].Value)
+	yes, err := getYesOpt(&argsMap)
+	if err != nil {
 
-	if nil == err {
+		return newCommandError("DeleteJob", err)
+	}
+
+	if !yes {
 
-		client.output.printInfo("Deleted job: " + *argsMap["job_id"].Value + " in project: " +
-			*argsMap["project_id"].Value)
+		err = confirmDelete("DeleteJob", "job", *argsMap["job_id"].Value,
+			func() (interface{}, *http.Response, error) {
 
-	} else {
+				job, resp, err := client.client.TtsApi.Job(client.ctx, *argsMap["project_id"].Value,
+					*argsMap["job_id"].Value)
+				return &job, resp, err
+			})
+		if err != nil {
 
-		client.output.printError("DeleteJob", err)
+			return err
+		}
 	}
+
+	resp, err := client.client.TtsApi.DeleteJob(client.ctx, *argsMap["project_id"].Value,
+		*argsMap["job_id"].Value)
+
+	if err != nil {
+
+		return newApiError("DeleteJob", resp, err)
+	}
+
+	client.output.printInfo("Deleted job: " + *argsMap["job_id"].Value + " in project: " +
+		*argsMap["project_id"].Value)
+
+	return nil
 }
 
 // Get delete job input attributes
 func (client *TtsClient) GetDeleteJobProperties() map[string]bool {
 
-	flagMap := map[string]bool{"project_id": true, "job_id": true}
+	flagMap := map[string]bool{"project_id": true, "job_id": true, "yes": false, "force": false}
 
 	return flagMap
 }
 
 // Print job result on output
-func (client *TtsClient) JobResult(argsMap cli.FlagMap) {
+func (client *TtsClient) JobResult(argsMap cli.FlagMap) error {
 
-	jobResult, _, err := client.client.TtsApi.JobResult(client.ctx, *argsMap["project_id"].Value,
+	jobResult, resp, err := client.client.TtsApi.JobResult(client.ctx, *argsMap["project_id"].Value,
 		*argsMap["job_id"].Value)
 
-	if nil == err {
+	if err != nil {
 
-		client.output.printStructContent(&jobResult)
+		return newApiError("JobResult", resp, err)
+	}
 
-	} else {
+	client.output.printStructContent(&jobResult)
 
-		client.output.printError("JobResult", err)
-	}
+	return nil
 }
 
 // Get job result input attributes
@@ -328,22 +501,22 @@ func (client *TtsClient) GetJobResultProperties() map[string]bool {
 }
 
 // Print job output on output
-func (client *TtsClient) JobOutputs(argsMap cli.FlagMap) {
+func (client *TtsClient) JobOutputs(argsMap cli.FlagMap) error {
 
-	jobOutputs, _, err := client.client.TtsApi.JobOutputs(client.ctx, *argsMap["project_id"].Value,
+	jobOutputs, resp, err := client.client.TtsApi.JobOutputs(client.ctx, *argsMap["project_id"].Value,
 		*argsMap["job_id"].Value)
 
-	if nil == err {
+	if err != nil {
 
-		for _, output := range jobOutputs {
-
-			client.output.printStructContent(&output)
-		}
+		return newApiError("JobOutputs", resp, err)
+	}
 
-	} else {
+	for _, output := range jobOutputs {
 
-		client.output.printError("JobResult", err)
+		client.output.printStructContent(&output)
 	}
+
+	return nil
 }
 
 // Get job result input attributes
@@ -355,26 +528,26 @@ func (client *TtsClient) GetJobOutputsProperties() map[string]bool {
 }
 
 // List all corpora
-func (client *TtsClient) ListCorpora(argsMap cli.FlagMap) {
+func (client *TtsClient) ListCorpora(argsMap cli.FlagMap) error {
 
-	corporaCollection, _, err := client.client.TtsApi.Corpora(client.ctx, *argsMap["project_id"].Value)
+	corporaCollection, resp, err := client.client.TtsApi.Corpora(client.ctx, *argsMap["project_id"].Value)
 
 	// print all corpora in table
 	colNames := []interface{}{"NAME", "STATUS"}
 	rows := [][]interface{}{}
 
-	if nil == err {
+	if err != nil {
 
-		for _, corpus := range corporaCollection.Corpora {
-			rows = append(rows, []interface{}{corpus.Name, corpus.Status})
-		}
+		return newApiError("ListCorpora", resp, err)
+	}
 
-		client.output.printTable(colNames, rows)
+	for _, corpus := range corporaCollection.Corpora {
+		rows = append(rows, []interface{}{corpus.Name, corpus.Status})
+	}
 
-	} else {
+	client.output.printCollection(&corporaCollection, colNames, rows)
 
-		client.output.printError("ListCorpora", err)
-	}
+	return nil
 }
 
 // Get list corpora input attributes
@@ -386,19 +559,19 @@ func (client *TtsClient) GetListCorporaProperties() map[string]bool {
 }
 
 // Print corpus description on output
-func (client *TtsClient) DescribeCorpus(argsMap cli.FlagMap) {
+func (client *TtsClient) DescribeCorpus(argsMap cli.FlagMap) error {
 
-	corpusDesc, _, err := client.client.TtsApi.Corpus(client.ctx, *argsMap["project_id"].Value,
+	corpusDesc, resp, err := client.client.TtsApi.Corpus(client.ctx, *argsMap["project_id"].Value,
 		*argsMap["corpus_name"].Value)
 
-	if nil == err {
+	if err != nil {
 
-		client.output.printStructContent(&corpusDesc)
+		return newApiError("DescribeCorpus", resp, err)
+	}
 
-	} else {
+	client.output.printStructContent(&corpusDesc)
 
-		client.output.printError("DescribeCorpus", err)
-	}
+	return nil
 }
 
 // Get describe corpus input attributes
@@ -410,20 +583,20 @@ func (client *TtsClient) GetDescribeCorpusProperties() map[string]bool {
 }
 
 // Create new corpus
-func (client *TtsClient) CreateCorpus(argsMap cli.FlagMap) {
+func (client *TtsClient) CreateCorpus(argsMap cli.FlagMap) error {
 
-	_, err := client.client.TtsApi.CreateCorpus(client.ctx, *argsMap["project_id"].Value,
+	resp, err := client.client.TtsApi.CreateCorpus(client.ctx, *argsMap["project_id"].Value,
 		*argsMap["corpus_name"].Value, *argsMap["corpus_body"].Value)
 
-	if nil == err {
+	if err != nil {
 
-		client.output.printInfo("Corpus created: " + *argsMap["corpus_name"].Value + " in project: " +
-			*argsMap["project_id"].Value)
+		return newApiError("CreateCorpus", resp, err)
+	}
 
-	} else {
+	client.output.printInfo("Corpus created: " + *argsMap["corpus_name"].Value + " in project: " +
+		*argsMap["project_id"].Value)
 
-		client.output.printError("CreateCorpus ", err)
-	}
+	return nil
 }
 
 // Get create corpus input attributes
@@ -435,26 +608,47 @@ func (client *TtsClient) GetCreateCorpusProperties() map[string]bool {
 }
 
 // Delete corpus
-func (client *TtsClient) DeleteCorpus(argsMap cli.FlagMap) {
+func (client *TtsClient) DeleteCorpus(argsMap cli.FlagMap) error {
 
-	_, err := client.client.TtsApi.DeleteCorpus(client.ctx, *argsMap["project_id"].Value,
-		*argsMap["corpus_name"].Value)
+	yes, err := getYesOpt(&argsMap)
+	if err != nil {
+
+		return newCommandError("DeleteCorpus", err)
+	}
+
+	if !yes {
 
-	if nil == err {
+		err = confirmDelete("DeleteCorpus", "corpus", *argsMap["corpus_name"].Value,
+			func() (interface{}, *http.Response, error) {
 
-		client.output.printInfo("Corpus deleted: " + *argsMap["corpus_name"].Value + " in project: " +
-			*argsMap["project_id"].Value)
+				corpus, resp, err := client.client.TtsApi.Corpus(client.ctx, *argsMap["project_id"].Value,
+					*argsMap["corpus_name"].Value)
+				return &corpus, resp, err
+			})
+		if err != nil {
 
-	} else {
+			return err
+		}
+	}
+
+	resp, err := client.client.TtsApi.DeleteCorpus(client.ctx, *argsMap["project_id"].Value,
+		*argsMap["corpus_name"].Value)
 
-		client.output.printError("DeleteCorpus", err)
+	if err != nil {
+
+		return newApiError("DeleteCorpus", resp, err)
 	}
+
+	client.output.printInfo("Corpus deleted: " + *argsMap["corpus_name"].Value + " in project: " +
+		*argsMap["project_id"].Value)
+
+	return nil
 }
 
 // Get delete corpus input attributes
 func (client *TtsClient) GetDeleteCorpusProperties() map[string]bool {
 
-	flagMap := map[string]bool{"project_id": true, "corpus_name": true}
+	flagMap := map[string]bool{"project_id": true, "corpus_name": true, "yes": false, "force": false}
 
 	return flagMap
 }